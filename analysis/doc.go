@@ -0,0 +1,3 @@
+// Package analysis provides Streamers which measure the audio passing through them, such as level
+// meters, for the Beep library.
+package analysis
@@ -0,0 +1,316 @@
+package analysis
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/faiface/beep"
+)
+
+// NewLoudness returns a Loudness which streams s unchanged and measures its loudness according to
+// ITU-R BS.1770 and EBU R128. The sample rate sr must match the sample rate of s.
+//
+// Use it as a pass-through meter,
+//
+//   loudness := analysis.NewLoudness(format.SampleRate, streamer)
+//   speaker.Play(loudness)
+//   // in the UI loop
+//   fmt.Printf("%.1f LUFS\n", loudness.ShortTerm())
+//
+// or use ScanLoudness to measure a whole file offline.
+//
+// The returned Loudness propagates s's errors.
+func NewLoudness(sr beep.SampleRate, s beep.Streamer) *Loudness {
+	l := &Loudness{
+		s:        s,
+		blockLen: sr.N(time.Second / 10),
+	}
+	l.filter = kWeighting(float64(sr))
+	l.Reset()
+	return l
+}
+
+// LoudnessStats are the results of a loudness measurement. Loudness values are in LUFS, Range is
+// in LU and the peaks are linear amplitudes. Loudness values are negative infinity when there was
+// not enough audio (or too quiet audio) to measure them.
+type LoudnessStats struct {
+	// Integrated is the gated loudness of the whole measured audio.
+	Integrated float64
+
+	// Range is the loudness range (LRA), the spread of the short-term loudness.
+	Range float64
+
+	// MaxMomentary and MaxShortTerm are the maximums of the momentary and short-term loudness.
+	MaxMomentary float64
+	MaxShortTerm float64
+
+	// Peak is the highest absolute sample value.
+	Peak float64
+
+	// TruePeak is the highest absolute value of the signal estimated by 4x oversampling.
+	TruePeak float64
+}
+
+// ScanLoudness streams s until it's drained and returns its loudness statistics. The sample rate
+// sr must match the sample rate of s.
+//
+// ScanLoudness returns s's error, if any.
+func ScanLoudness(sr beep.SampleRate, s beep.Streamer) (LoudnessStats, error) {
+	l := NewLoudness(sr, s)
+	var buf [512][2]float64
+	for {
+		if _, ok := l.Stream(buf[:]); !ok {
+			break
+		}
+	}
+	if err := l.Err(); err != nil {
+		return LoudnessStats{}, err
+	}
+	return l.Stats(), nil
+}
+
+// Loudness is a pass-through Streamer created by NewLoudness. Its measurements can be read from
+// any goroutine while it's being streamed.
+type Loudness struct {
+	s        beep.Streamer
+	filter   [2]biquad
+	state    [2][2]biquadState // state of the two filter stages for both channels
+	tp       truePeakDetector
+	blockLen int // length of a 100ms block
+
+	// accumulated over the current 100ms block
+	count int
+	sum   float64
+
+	mu           sync.Mutex // guards the fields below
+	blocks       []float64  // mean square of each 100ms block
+	momentary    []float64  // mean square of each 400ms block, every 100ms
+	shortTerm    []float64  // mean square of each 3s block, every second
+	maxMomentary float64
+	maxShortTerm float64
+	peak         float64
+	truePeak     float64
+}
+
+// Reset clears all the measurements.
+func (l *Loudness) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.blocks = nil
+	l.momentary = nil
+	l.shortTerm = nil
+	l.maxMomentary = math.Inf(-1)
+	l.maxShortTerm = math.Inf(-1)
+	l.peak = 0
+	l.truePeak = 0
+}
+
+// Stream streams the wrapped Streamer unchanged, measuring its loudness.
+func (l *Loudness) Stream(samples [][2]float64) (n int, ok bool) {
+	n, ok = l.s.Stream(samples)
+	peak, truePeak := 0.0, 0.0
+	for _, x := range samples[:n] {
+		tp := l.tp.push(x)
+		for c := range x {
+			peak = math.Max(peak, math.Abs(x[c]))
+			truePeak = math.Max(truePeak, tp[c])
+			y := l.filter[0].process(&l.state[0][c], x[c])
+			y = l.filter[1].process(&l.state[1][c], y)
+			l.sum += y * y
+		}
+		l.count++
+		if l.count == l.blockLen {
+			l.addBlock(l.sum / float64(l.count))
+			l.count, l.sum = 0, 0
+		}
+	}
+
+	l.mu.Lock()
+	l.peak = math.Max(l.peak, peak)
+	l.truePeak = math.Max(l.truePeak, truePeak)
+	l.mu.Unlock()
+	return n, ok
+}
+
+// addBlock adds the mean square of a 100ms block and updates the measurements.
+func (l *Loudness) addBlock(ms float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.blocks = append(l.blocks, ms)
+	if len(l.blocks) >= 4 {
+		ms := mean(l.blocks[len(l.blocks)-4:])
+		l.momentary = append(l.momentary, ms)
+		l.maxMomentary = math.Max(l.maxMomentary, lufs(ms))
+	}
+	if len(l.blocks) >= 30 {
+		ms := mean(l.blocks[len(l.blocks)-30:])
+		l.maxShortTerm = math.Max(l.maxShortTerm, lufs(ms))
+		if (len(l.blocks)-30)%10 == 0 {
+			l.shortTerm = append(l.shortTerm, ms)
+		}
+	}
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (l *Loudness) Err() error {
+	return l.s.Err()
+}
+
+// Momentary returns the loudness of the last 400ms in LUFS.
+func (l *Loudness) Momentary() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.blocks) < 4 {
+		return math.Inf(-1)
+	}
+	return lufs(mean(l.blocks[len(l.blocks)-4:]))
+}
+
+// ShortTerm returns the loudness of the last 3 seconds in LUFS.
+func (l *Loudness) ShortTerm() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.blocks) < 30 {
+		return math.Inf(-1)
+	}
+	return lufs(mean(l.blocks[len(l.blocks)-30:]))
+}
+
+// Integrated returns the gated loudness of everything measured so far in LUFS.
+func (l *Loudness) Integrated() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.integrated()
+}
+
+// Range returns the loudness range (LRA) of everything measured so far in LU.
+func (l *Loudness) Range() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.loudnessRange()
+}
+
+// Stats returns all the measurements at once.
+func (l *Loudness) Stats() LoudnessStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return LoudnessStats{
+		Integrated:   l.integrated(),
+		Range:        l.loudnessRange(),
+		MaxMomentary: l.maxMomentary,
+		MaxShortTerm: l.maxShortTerm,
+		Peak:         l.peak,
+		TruePeak:     l.truePeak,
+	}
+}
+
+// integrated gates the 400ms blocks first by the absolute threshold of -70 LUFS and then by the
+// relative threshold of 10 LU below their loudness.
+func (l *Loudness) integrated() float64 {
+	gated := gate(l.momentary, -10)
+	if len(gated) == 0 {
+		return math.Inf(-1)
+	}
+	return lufs(mean(gated))
+}
+
+// loudnessRange gates the 3s blocks first by the absolute threshold of -70 LUFS and then by the
+// relative threshold of 20 LU below their loudness, and returns the difference between the 95th
+// and the 10th percentile of the loudness of the rest, as specified by EBU Tech 3342.
+func (l *Loudness) loudnessRange() float64 {
+	gated := gate(l.shortTerm, -20)
+	if len(gated) == 0 {
+		return 0
+	}
+	loudness := make([]float64, len(gated))
+	for i := range gated {
+		loudness[i] = lufs(gated[i])
+	}
+	sort.Float64s(loudness)
+	percentile := func(p float64) float64 {
+		return loudness[int(math.Round(p*float64(len(loudness)-1)))]
+	}
+	return percentile(0.95) - percentile(0.10)
+}
+
+// gate returns the mean squares of blocks louder than -70 LUFS and than the relative threshold
+// below the loudness of those.
+func gate(blocks []float64, relative float64) []float64 {
+	var abs []float64
+	for _, ms := range blocks {
+		if lufs(ms) > -70 {
+			abs = append(abs, ms)
+		}
+	}
+	if len(abs) == 0 {
+		return nil
+	}
+	threshold := lufs(mean(abs)) + relative
+	var rel []float64
+	for _, ms := range abs {
+		if lufs(ms) > threshold {
+			rel = append(rel, ms)
+		}
+	}
+	return rel
+}
+
+// lufs converts the sum of the mean squares of the K-weighted channels to LUFS.
+func lufs(ms float64) float64 {
+	return -0.691 + 10*math.Log10(ms)
+}
+
+func mean(x []float64) float64 {
+	sum := 0.0
+	for _, v := range x {
+		sum += v
+	}
+	return sum / float64(len(x))
+}
+
+// kWeighting returns the two stages of the K-weighting filter for the sample rate fs. The
+// coefficients are derived for any sample rate the same way as in libebur128.
+func kWeighting(fs float64) [2]biquad {
+	var stages [2]biquad
+
+	// high shelf, modeling the acoustic effects of the head
+	f0, g, q := 1681.974450955533, 3.999843853973347, 0.7071752369554196
+	k := math.Tan(math.Pi * f0 / fs)
+	vh := math.Pow(10, g/20)
+	vb := math.Pow(vh, 0.4996667741545416)
+	a0 := 1 + k/q + k*k
+	stages[0] = biquad{
+		b: [3]float64{(vh + vb*k/q + k*k) / a0, 2 * (k*k - vh) / a0, (vh - vb*k/q + k*k) / a0},
+		a: [2]float64{2 * (k*k - 1) / a0, (1 - k/q + k*k) / a0},
+	}
+
+	// high pass, the revised low-frequency B-curve
+	f0, q = 38.13547087602444, 0.5003270373238773
+	k = math.Tan(math.Pi * f0 / fs)
+	a0 = 1 + k/q + k*k
+	stages[1] = biquad{
+		b: [3]float64{1, -2, 1},
+		a: [2]float64{2 * (k*k - 1) / a0, (1 - k/q + k*k) / a0},
+	}
+
+	return stages
+}
+
+// biquad is a second order IIR filter. The coefficient a0 is normalized to 1.
+type biquad struct {
+	b [3]float64
+	a [2]float64
+}
+
+type biquadState struct {
+	x, y [2]float64 // the last two inputs and outputs
+}
+
+func (f *biquad) process(st *biquadState, x float64) float64 {
+	y := f.b[0]*x + f.b[1]*st.x[0] + f.b[2]*st.x[1] - f.a[0]*st.y[0] - f.a[1]*st.y[1]
+	st.x[1], st.x[0] = st.x[0], x
+	st.y[1], st.y[0] = st.y[0], y
+	return y
+}
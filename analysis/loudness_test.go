@@ -0,0 +1,75 @@
+package analysis_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/analysis"
+)
+
+func TestLoudnessSine(t *testing.T) {
+	// a 1 kHz sine at -20 dBFS in both channels measures -20 LUFS
+	sr := beep.SampleRate(48000)
+	stats, err := analysis.ScanLoudness(sr, sine(sr, 20*time.Second, 1000, math.Pow(10, -20.0/20), 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(stats.Integrated+20) > 0.1 {
+		t.Errorf("Integrated %v LUFS, expected -20", stats.Integrated)
+	}
+	if math.Abs(stats.MaxMomentary+20) > 0.1 || math.Abs(stats.MaxShortTerm+20) > 0.1 {
+		t.Errorf("MaxMomentary %v and MaxShortTerm %v LUFS, expected -20", stats.MaxMomentary, stats.MaxShortTerm)
+	}
+	if stats.Range > 0.1 {
+		t.Errorf("Range %v LU of a steady sine, expected 0", stats.Range)
+	}
+	if math.Abs(analysis.DB(stats.Peak)+20) > 0.01 {
+		t.Errorf("Peak %v dBFS, expected -20", analysis.DB(stats.Peak))
+	}
+}
+
+func TestLoudnessGating(t *testing.T) {
+	sr := beep.SampleRate(48000)
+	at := func(d time.Duration, db float64) beep.Streamer {
+		return sine(sr, d, 1000, math.Pow(10, db/20), 0)
+	}
+
+	// the silence and the part below the absolute gate of -70 LUFS don't count
+	stats, err := analysis.ScanLoudness(sr, beep.Seq(
+		at(10*time.Second, -20),
+		beep.Silence(sr.N(10*time.Second)),
+		at(10*time.Second, -80),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(stats.Integrated+20) > 0.1 {
+		t.Errorf("Integrated %v LUFS with gated parts, expected -20", stats.Integrated)
+	}
+
+	// EBU Tech 3342, case 1: the loudness range of 20 s at -20 and 20 s at -30 LUFS is 10 LU
+	stats, err = analysis.ScanLoudness(sr, beep.Seq(at(20*time.Second, -20), at(20*time.Second, -30)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(stats.Range-10) > 0.1 {
+		t.Errorf("Range %v LU, expected 10", stats.Range)
+	}
+}
+
+func TestLoudnessTruePeak(t *testing.T) {
+	// the peaks of a sine at a quarter of the sample rate can fall between the samples
+	sr := beep.SampleRate(48000)
+	stats, err := analysis.ScanLoudness(sr, sine(sr, time.Second, 12000, 0.5, math.Pi/4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(stats.Peak-0.5*math.Sqrt2/2) > 1e-9 {
+		t.Errorf("Peak %v, expected %v", stats.Peak, 0.5*math.Sqrt2/2)
+	}
+	if math.Abs(stats.TruePeak-0.5) > 0.025 {
+		t.Errorf("TruePeak %v, expected 0.5", stats.TruePeak)
+	}
+}
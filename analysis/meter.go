@@ -0,0 +1,205 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/faiface/beep"
+)
+
+// Levels are the levels of the left and the right channel measured by a Meter. All the levels
+// are linear amplitudes, use DB to convert them to decibels.
+type Levels struct {
+	// Peak is the highest absolute sample value in the last window.
+	Peak [2]float64
+
+	// RMS is the root mean square of the samples in the last window.
+	RMS [2]float64
+
+	// TruePeak is the highest absolute value of the signal in the last window, estimated by 4x
+	// oversampling. Unlike Peak, it catches the peaks between the samples, which show up as
+	// clipping after conversion to analog or lossy encoding.
+	TruePeak [2]float64
+
+	// Hold is the peak-hold indicator. It jumps to TruePeak whenever TruePeak gets above it, stays
+	// there for the hold time and then decays at the decay rate.
+	Hold [2]float64
+}
+
+// DB converts a linear amplitude to decibels relative to full scale. Zero converts to negative
+// infinity.
+func DB(level float64) float64 {
+	return 20 * math.Log10(level)
+}
+
+// NewMeter returns a Meter which streams s unchanged and measures its levels over consecutive
+// windows of window samples. The sample rate sr is needed for the peak-hold ballistics, which
+// default to holding for one second and decaying at 12 dB per second.
+//
+//   meter := analysis.NewMeter(format.SampleRate, format.SampleRate.N(time.Second/20), streamer)
+//   speaker.Play(meter)
+//   // in the UI loop
+//   levels := meter.Levels()
+//   drawBar(analysis.DB(levels.RMS[0]), analysis.DB(levels.Hold[0]))
+//
+// NewMeter panics if window is not positive.
+//
+// The returned Meter propagates s's errors.
+func NewMeter(sr beep.SampleRate, window int, s beep.Streamer) *Meter {
+	if window <= 0 {
+		panic(fmt.Errorf("meter: invalid window: %d", window))
+	}
+	return &Meter{
+		s:      s,
+		sr:     sr,
+		window: window,
+		hold:   sr.N(time.Second),
+		decay:  12,
+	}
+}
+
+// Meter is a pass-through Streamer created by NewMeter. Its Levels can be read from any goroutine
+// while it's being streamed.
+type Meter struct {
+	s      beep.Streamer
+	sr     beep.SampleRate
+	window int
+
+	// accumulated over the current window
+	count    int
+	peak     [2]float64
+	sum      [2]float64
+	truePeak [2]float64
+
+	tp truePeakDetector
+
+	mu       sync.Mutex // guards the fields below
+	hold     int
+	decay    float64
+	holdLeft [2]int
+	levels   Levels
+}
+
+// SetBallistics sets the time for which the peak-hold indicator holds a peak and the rate in
+// decibels per second at which it decays afterwards.
+func (m *Meter) SetBallistics(hold time.Duration, decay float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hold = m.sr.N(hold)
+	m.decay = decay
+}
+
+// Levels returns the levels measured in the last complete window.
+func (m *Meter) Levels() Levels {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.levels
+}
+
+// Reset clears the measured levels, including the peak-hold indicator.
+func (m *Meter) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.levels = Levels{}
+	m.holdLeft = [2]int{}
+}
+
+// Stream streams the wrapped Streamer unchanged, measuring its levels.
+func (m *Meter) Stream(samples [][2]float64) (n int, ok bool) {
+	n, ok = m.s.Stream(samples)
+	for _, x := range samples[:n] {
+		tp := m.tp.push(x)
+		for c := range x {
+			if a := math.Abs(x[c]); a > m.peak[c] {
+				m.peak[c] = a
+			}
+			m.sum[c] += x[c] * x[c]
+			if tp[c] > m.truePeak[c] {
+				m.truePeak[c] = tp[c]
+			}
+		}
+		m.count++
+		if m.count == m.window {
+			m.publish()
+		}
+	}
+	return n, ok
+}
+
+// publish publishes the levels measured in the current window and starts a new one.
+func (m *Meter) publish() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for c := range m.levels.Peak {
+		m.levels.Peak[c] = m.peak[c]
+		m.levels.RMS[c] = math.Sqrt(m.sum[c] / float64(m.count))
+		m.levels.TruePeak[c] = m.truePeak[c]
+
+		switch {
+		case m.truePeak[c] >= m.levels.Hold[c]:
+			m.levels.Hold[c] = m.truePeak[c]
+			m.holdLeft[c] = m.hold
+		case m.holdLeft[c] > 0:
+			m.holdLeft[c] -= m.count
+		default:
+			seconds := m.sr.D(m.count).Seconds()
+			m.levels.Hold[c] *= math.Pow(10, -m.decay*seconds/20)
+		}
+	}
+	m.count = 0
+	m.peak = [2]float64{}
+	m.sum = [2]float64{}
+	m.truePeak = [2]float64{}
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (m *Meter) Err() error {
+	return m.s.Err()
+}
+
+// truePeakTaps is the number of taps of each phase of the oversampling filter.
+const truePeakTaps = 12
+
+// truePeakDetector estimates the true peak of a signal by 4x oversampling.
+type truePeakDetector struct {
+	history [truePeakTaps][2]float64 // the last samples, for the oversampling filter
+	i       int                      // index of the oldest sample in history
+}
+
+// push adds the sample x to the history and returns the highest absolute values of the signal
+// around it, including the sample itself.
+func (d *truePeakDetector) push(x [2]float64) (peak [2]float64) {
+	d.history[d.i] = x
+	d.i = (d.i + 1) % truePeakTaps
+	for c := range x {
+		peak[c] = math.Abs(x[c])
+		for p := range truePeakFilter {
+			y := 0.0
+			for j, h := range truePeakFilter[p] {
+				y += h * d.history[(d.i+j)%truePeakTaps][c]
+			}
+			if y := math.Abs(y); y > peak[c] {
+				peak[c] = y
+			}
+		}
+	}
+	return peak
+}
+
+// truePeakFilter is a Hann windowed sinc interpolation filter. truePeakFilter[p] interpolates the
+// value (p+1)/4 of a sample after the middle of the history, the samples themselves are measured
+// directly.
+var truePeakFilter = func() (filter [3][truePeakTaps]float64) {
+	const half = truePeakTaps / 2
+	for p := range filter {
+		for j := range filter[p] {
+			// distance from the history sample j to the interpolated point
+			u := float64(half-1-j) + float64(p+1)/4
+			w := 0.5 * (1 + math.Cos(math.Pi*u/half))
+			filter[p][j] = math.Sin(math.Pi*u) / (math.Pi * u) * w
+		}
+	}
+	return filter
+}()
@@ -0,0 +1,74 @@
+package analysis_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/analysis"
+)
+
+// sine returns a Streamer of d of a sine wave with the frequency freq in Hz, the amplitude amp and
+// the phase offset phase in radians, the same in both channels.
+func sine(sr beep.SampleRate, d time.Duration, freq, amp, phase float64) beep.Streamer {
+	i := 0
+	return beep.Take(sr.N(d), beep.StreamerFunc(func(samples [][2]float64) (n int, ok bool) {
+		for j := range samples {
+			x := amp * math.Sin(2*math.Pi*freq*float64(i)/float64(sr)+phase)
+			samples[j] = [2]float64{x, x}
+			i++
+		}
+		return len(samples), true
+	}))
+}
+
+// drain streams s until it's drained.
+func drain(s beep.Streamer) {
+	var buf [512][2]float64
+	for {
+		if _, ok := s.Stream(buf[:]); !ok {
+			return
+		}
+	}
+}
+
+func TestMeter(t *testing.T) {
+	// a sine at a quarter of the sample rate with its peaks exactly between the samples
+	sr := beep.SampleRate(48000)
+	meter := analysis.NewMeter(sr, sr.N(time.Second/10), sine(sr, time.Second, 12000, 1, math.Pi/4))
+	drain(meter)
+
+	levels := meter.Levels()
+	for c := 0; c < 2; c++ {
+		if math.Abs(levels.Peak[c]-math.Sqrt2/2) > 1e-9 {
+			t.Errorf("Peak %v, expected %v", levels.Peak[c], math.Sqrt2/2)
+		}
+		if math.Abs(levels.RMS[c]-math.Sqrt2/2) > 1e-9 {
+			t.Errorf("RMS %v, expected %v", levels.RMS[c], math.Sqrt2/2)
+		}
+		if math.Abs(levels.TruePeak[c]-1) > 0.05 {
+			t.Errorf("TruePeak %v, expected 1", levels.TruePeak[c])
+		}
+		if levels.Hold[c] < levels.TruePeak[c] {
+			t.Errorf("Hold %v below TruePeak %v", levels.Hold[c], levels.TruePeak[c])
+		}
+	}
+}
+
+func TestMeterHold(t *testing.T) {
+	sr := beep.SampleRate(48000)
+	window := sr.N(time.Second / 10)
+	meter := analysis.NewMeter(sr, window, beep.Seq(
+		sine(sr, time.Second/10, 1000, 1, 0),
+		beep.Silence(sr.N(time.Second)),
+	))
+	meter.SetBallistics(time.Second/2, 20)
+	drain(meter)
+
+	// held for half a second of the second, then decaying for the rest
+	want := math.Pow(10, -20*0.5/20)
+	if hold := meter.Levels().Hold[0]; math.Abs(hold-want) > 0.05 {
+		t.Errorf("Hold %v after decaying, expected about %v", hold, want)
+	}
+}
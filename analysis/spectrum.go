@@ -0,0 +1,108 @@
+package analysis
+
+import (
+	"math/cmplx"
+	"sync"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/fft"
+)
+
+// NewSpectrum returns a Spectrum which streams s unchanged and analyzes the frequency content of
+// its mono mix. The analysis uses frames of size samples (a power of two) windowed by the Hann
+// window, starting every hop samples. The sample rate sr must match the sample rate of s.
+//
+//   spectrum := analysis.NewSpectrum(format.SampleRate, 2048, 512, streamer)
+//   speaker.Play(spectrum)
+//   // in the UI loop
+//   for i, m := range spectrum.Frame() {
+//       drawBar(spectrum.Frequency(i), analysis.DB(m))
+//   }
+//
+// NewSpectrum panics if size is not a power of two of at least 2, or hop is not between 1 and size.
+//
+// The returned Spectrum propagates s's errors.
+func NewSpectrum(sr beep.SampleRate, size, hop int, s beep.Streamer) *Spectrum {
+	stft := fft.NewSTFT(size, hop, fft.Hann)
+	sum := 0.0
+	for _, w := range stft.Window() {
+		sum += w
+	}
+	return &Spectrum{
+		s:     s,
+		sr:    sr,
+		stft:  stft,
+		scale: 2 / sum,
+		mono:  make([]float64, 512),
+		frame: make([]float64, size/2+1),
+	}
+}
+
+// Spectrum is a pass-through Streamer created by NewSpectrum. Its frames can be read from any
+// goroutine while it's being streamed.
+type Spectrum struct {
+	s     beep.Streamer
+	sr    beep.SampleRate
+	stft  *fft.STFT
+	scale float64 // normalizes the magnitudes, so that a full scale sine wave has magnitude 1
+	mono  []float64
+
+	mu     sync.Mutex // guards the fields below
+	frame  []float64
+	frames int
+}
+
+// Stream streams the wrapped Streamer unchanged, analyzing its spectrum.
+func (sp *Spectrum) Stream(samples [][2]float64) (n int, ok bool) {
+	n, ok = sp.s.Stream(samples)
+	for done := 0; done < n; {
+		k := n - done
+		if k > len(sp.mono) {
+			k = len(sp.mono)
+		}
+		for i := range sp.mono[:k] {
+			x := samples[done+i]
+			sp.mono[i] = (x[0] + x[1]) / 2
+		}
+		sp.stft.Push(sp.mono[:k], sp.publish)
+		done += k
+	}
+	return n, ok
+}
+
+// publish replaces the published frame with the magnitudes of the spectrum.
+func (sp *Spectrum) publish(spectrum []complex128) {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	for i, c := range spectrum {
+		sp.frame[i] = cmplx.Abs(c) * sp.scale
+	}
+	sp.frames++
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (sp *Spectrum) Err() error {
+	return sp.s.Err()
+}
+
+// Frame returns a copy of the magnitudes of the last analyzed frame, from the frequency 0 up to
+// the Nyquist frequency. The magnitudes are linear amplitudes, a full scale sine wave has
+// magnitude 1.
+func (sp *Spectrum) Frame() []float64 {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	return append([]float64(nil), sp.frame...)
+}
+
+// Frames returns the number of frames analyzed so far. Visualizers can use it to tell whether a
+// new frame is available.
+func (sp *Spectrum) Frames() int {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	return sp.frames
+}
+
+// Frequency returns the frequency in Hz of the i-th magnitude of a frame.
+func (sp *Spectrum) Frequency(i int) float64 {
+	return float64(i) * float64(sp.sr) / float64(sp.stft.Len())
+}
@@ -0,0 +1,38 @@
+package analysis_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/analysis"
+)
+
+func TestSpectrum(t *testing.T) {
+	// a full scale sine exactly at the frequency of a bin has magnitude 1 there
+	sr := beep.SampleRate(48000)
+	bin, freq := 64, 3000.0
+	spectrum := analysis.NewSpectrum(sr, 1024, 256, sine(sr, time.Second/10, freq, 1, 0))
+	if spectrum.Frequency(bin) != freq {
+		t.Fatalf("Frequency(%d) = %v, expected %v", bin, spectrum.Frequency(bin), freq)
+	}
+
+	drain(spectrum)
+	if want := (sr.N(time.Second/10)-1024)/256 + 1; spectrum.Frames() != want {
+		t.Errorf("Frames() = %d, expected %d", spectrum.Frames(), want)
+	}
+	frame := spectrum.Frame()
+	if len(frame) != 513 {
+		t.Fatalf("frame has %d magnitudes, expected 513", len(frame))
+	}
+	max := 0
+	for i := range frame {
+		if frame[i] > frame[max] {
+			max = i
+		}
+	}
+	if max != bin || math.Abs(frame[bin]-1) > 1e-6 {
+		t.Errorf("peak %v at %d, expected 1 at %d", frame[max], max, bin)
+	}
+}
@@ -60,6 +60,32 @@ func (f Format) DecodeUnsigned(p []byte) (sample [2]float64, n int) {
 	return f.decode(false, p)
 }
 
+// EncodeSignedMulti encodes a single multichannel sample in f.Width() bytes to p in signed format.
+// If the sample has fewer channels than f.NumChannels, the missing channels are encoded as silence,
+// extra channels are ignored.
+func (f Format) EncodeSignedMulti(p []byte, sample []float64) (n int) {
+	return f.encodeMulti(true, p, sample)
+}
+
+// EncodeUnsignedMulti encodes a single multichannel sample in f.Width() bytes to p in unsigned
+// format. See EncodeSignedMulti for the handling of the channels.
+func (f Format) EncodeUnsignedMulti(p []byte, sample []float64) (n int) {
+	return f.encodeMulti(false, p, sample)
+}
+
+// DecodeSignedMulti decodes a single multichannel sample encoded in f.Width() bytes from p in
+// signed format into sample. If sample has fewer elements than f.NumChannels, the extra channels
+// are skipped. The remaining elements of sample are left untouched.
+func (f Format) DecodeSignedMulti(p []byte, sample []float64) (n int) {
+	return f.decodeMulti(true, p, sample)
+}
+
+// DecodeUnsignedMulti decodes a single multichannel sample encoded in f.Width() bytes from p in
+// unsigned format into sample. See DecodeSignedMulti for the handling of the channels.
+func (f Format) DecodeUnsignedMulti(p []byte, sample []float64) (n int) {
+	return f.decodeMulti(false, p, sample)
+}
+
 func (f Format) encode(signed bool, p []byte, sample [2]float64) (n int) {
 	switch {
 	case f.NumChannels == 1:
@@ -100,6 +126,34 @@ func (f Format) decode(signed bool, p []byte) (sample [2]float64, n int) {
 	}
 }
 
+func (f Format) encodeMulti(signed bool, p []byte, sample []float64) (n int) {
+	if f.NumChannels <= 0 {
+		panic(fmt.Errorf("format: encode: invalid number of channels: %d", f.NumChannels))
+	}
+	for c := 0; c < f.NumChannels; c++ {
+		x := 0.0
+		if c < len(sample) {
+			x = norm(sample[c])
+		}
+		p = p[encodeFloat(signed, f.Precision, p, x):]
+	}
+	return f.Width()
+}
+
+func (f Format) decodeMulti(signed bool, p []byte, sample []float64) (n int) {
+	if f.NumChannels <= 0 {
+		panic(fmt.Errorf("format: decode: invalid number of channels: %d", f.NumChannels))
+	}
+	for c := 0; c < f.NumChannels; c++ {
+		x, n := decodeFloat(signed, f.Precision, p)
+		if c < len(sample) {
+			sample[c] = x
+		}
+		p = p[n:]
+	}
+	return f.Width()
+}
+
 func encodeFloat(signed bool, precision int, p []byte, x float64) (n int) {
 	var xUint64 uint64
 	if signed {
@@ -206,6 +260,25 @@ func (b *Buffer) Append(s Streamer) {
 	}
 }
 
+// AppendMulti adds all audio data from the given MultiStreamer to the end of the Buffer. The
+// channels are stored in the order of m's layout, channels beyond the Buffer's NumChannels are
+// dropped.
+//
+// The MultiStreamer will be drained when this method finishes.
+func (b *Buffer) AppendMulti(m MultiStreamer) {
+	samples := MakeMultiSamples(512, m.Layout().NumChannels())
+	for {
+		n, ok := m.Stream(samples)
+		if !ok {
+			break
+		}
+		for _, sample := range samples[:n] {
+			b.f.EncodeSignedMulti(b.tmp, sample)
+			b.data = append(b.data, b.tmp...)
+		}
+	}
+}
+
 // Streamer returns a StreamSeeker which streams samples in the given interval (including from,
 // excluding to). If from<0 or to>b.Len() or to<from, this method panics.
 //
@@ -219,6 +292,24 @@ func (b *Buffer) Streamer(from, to int) StreamSeeker {
 	}
 }
 
+// MultiStreamer is the multichannel counterpart of Streamer. The Buffer doesn't remember the
+// layout of the appended data, so the returned MultiStreamSeeker has the given layout. The layout
+// must have b.Format().NumChannels channels, otherwise this method panics. This method also panics
+// under the same conditions as Streamer.
+func (b *Buffer) MultiStreamer(layout Layout, from, to int) MultiStreamSeeker {
+	if layout.NumChannels() != b.f.NumChannels {
+		panic(fmt.Errorf("buffer: layout has %d channels, format has %d", layout.NumChannels(), b.f.NumChannels))
+	}
+	return &bufferMultiStreamer{
+		bufferStreamer: bufferStreamer{
+			f:    b.f,
+			data: b.data[from*b.f.Width() : to*b.f.Width()],
+			pos:  0,
+		},
+		layout: layout,
+	}
+}
+
 type bufferStreamer struct {
 	f    Format
 	data []byte
@@ -260,3 +351,26 @@ func (bs *bufferStreamer) Seek(p int) error {
 	bs.pos = p * bs.f.Width()
 	return nil
 }
+
+type bufferMultiStreamer struct {
+	bufferStreamer
+	layout Layout
+}
+
+func (bs *bufferMultiStreamer) Stream(samples [][]float64) (n int, ok bool) {
+	if bs.pos >= len(bs.data) {
+		return 0, false
+	}
+	for i := range samples {
+		if bs.pos >= len(bs.data) {
+			break
+		}
+		bs.pos += bs.f.DecodeSignedMulti(bs.data[bs.pos:], samples[i])
+		n++
+	}
+	return n, true
+}
+
+func (bs *bufferMultiStreamer) Layout() Layout {
+	return bs.layout
+}
@@ -1,9 +1,28 @@
 package beep
 
+import "fmt"
+
 // Take returns a Streamer which streams at most num samples from s.
 //
+// If s is a StreamSeeker with at least num samples after its current position, the returned
+// Streamer is a StreamSeeker too. It still streams from wherever s currently is, so consecutive
+// Takes of the same StreamSeeker stream consecutive parts of it, and only seeking the Take seeks s,
+// relative to the position s had when the Take was created. Otherwise the length of s may not be
+// known (some StreamSeekers report zero length when their source can't seek), so the returned
+// Streamer isn't seekable.
+//
 // The returned Streamer propagates s's errors through Err.
 func Take(num int, s Streamer) Streamer {
+	if ss, ok := s.(StreamSeeker); ok && num >= 0 {
+		from := ss.Position()
+		if to := from + num; to >= from && to <= ss.Len() {
+			return &takeSeeker{
+				s:    ss,
+				from: from,
+				num:  num,
+			}
+		}
+	}
 	return &take{
 		s:       s,
 		remains: num,
@@ -32,6 +51,120 @@ func (t *take) Err() error {
 	return t.s.Err()
 }
 
+type takeSeeker struct {
+	s    StreamSeeker
+	from int // position of s where the Take starts
+	num  int
+	pos  int
+}
+
+func (t *takeSeeker) Stream(samples [][2]float64) (n int, ok bool) {
+	if t.pos >= t.num {
+		return 0, false
+	}
+	toStream := t.num - t.pos
+	if len(samples) < toStream {
+		toStream = len(samples)
+	}
+	n, ok = t.s.Stream(samples[:toStream])
+	t.pos += n
+	return n, ok
+}
+
+func (t *takeSeeker) Err() error {
+	return t.s.Err()
+}
+
+func (t *takeSeeker) Len() int {
+	return t.num
+}
+
+func (t *takeSeeker) Position() int {
+	return t.pos
+}
+
+func (t *takeSeeker) Seek(p int) error {
+	if p < 0 || t.num < p {
+		return fmt.Errorf("take: seek position %v out of range [%v, %v]", p, 0, t.num)
+	}
+	if err := t.s.Seek(t.from + p); err != nil {
+		return err
+	}
+	t.pos = p
+	return nil
+}
+
+// Slice returns a StreamSeeker which streams the samples of s between the positions from
+// (inclusive) and to (exclusive). Position 0 of the returned StreamSeeker is the position from of
+// s.
+//
+// The returned StreamSeeker seeks s whenever the position of s doesn't match its own, so several
+// Slices of the same StreamSeeker can be streamed one after another, for example with
+// SeekableSeq.
+//
+// Slice panics if from and to are not within the range [0, s.Len()] or from is greater than to.
+//
+// The returned StreamSeeker propagates s's errors, including seeking errors during streaming.
+func Slice(from, to int, s StreamSeeker) StreamSeeker {
+	if from < 0 || to < from || s.Len() < to {
+		panic(fmt.Errorf("slice: invalid range [%v, %v) of [%v, %v]", from, to, 0, s.Len()))
+	}
+	return &slice{
+		s:    s,
+		from: from,
+		to:   to,
+	}
+}
+
+type slice struct {
+	s        StreamSeeker
+	from, to int
+	pos      int
+	err      error
+}
+
+func (sl *slice) Stream(samples [][2]float64) (n int, ok bool) {
+	if sl.Err() != nil || sl.from+sl.pos >= sl.to {
+		return 0, false
+	}
+	if sl.s.Position() != sl.from+sl.pos {
+		if err := sl.s.Seek(sl.from + sl.pos); err != nil {
+			sl.err = err
+			return 0, false
+		}
+	}
+	toStream := sl.to - sl.from - sl.pos
+	if len(samples) < toStream {
+		toStream = len(samples)
+	}
+	n, ok = sl.s.Stream(samples[:toStream])
+	sl.pos += n
+	return n, ok
+}
+
+func (sl *slice) Err() error {
+	if sl.err != nil {
+		return sl.err
+	}
+	return sl.s.Err()
+}
+
+func (sl *slice) Len() int {
+	return sl.to - sl.from
+}
+
+func (sl *slice) Position() int {
+	return sl.pos
+}
+
+func (sl *slice) Seek(p int) error {
+	if p < 0 || sl.Len() < p {
+		return fmt.Errorf("slice: seek position %v out of range [%v, %v]", p, 0, sl.Len())
+	}
+	sl.pos = p
+	return nil
+}
+
 // Loop takes a StreamSeeker and plays it count times. If count is negative, s is looped infinitely.
 //
 // The returned Streamer propagates s's errors.
@@ -76,6 +209,173 @@ func (l *loop) Err() error {
 	return l.s.Err()
 }
 
+// LoopRegion takes a StreamSeeker and returns a Looper, which streams s from its current position,
+// but plays the region between start (inclusive) and end (exclusive) count times before
+// continuing past the end. If count is negative, the region is looped until Exit is called. If
+// count is 0, the Looper streams nothing, like Loop.
+//
+// This is useful for music with an intro, a looping body and an ending.
+//
+//   looper := beep.LoopRegion(-1, introEnd, bodyEnd, song)
+//   speaker.Play(looper)
+//   // ...
+//   speaker.Lock()
+//   looper.Exit() // plays the rest of the body and then the ending
+//   speaker.Unlock()
+//
+// LoopRegion panics if start and end are not within the range [0, s.Len()] or start is not less
+// than end.
+//
+// The returned Looper propagates s's errors.
+func LoopRegion(count, start, end int, s StreamSeeker) *Looper {
+	if start < 0 || end <= start || s.Len() < end {
+		panic(fmt.Errorf("loop region: invalid region [%v, %v) of [%v, %v]", start, end, 0, s.Len()))
+	}
+	return &Looper{
+		s:       s,
+		start:   start,
+		end:     end,
+		remains: count - 1,
+		done:    count == 0,
+		curve:   LinearCurve,
+	}
+}
+
+// Looper is a Streamer created by LoopRegion.
+type Looper struct {
+	s          StreamSeeker
+	start, end int
+	remains    int // number of jumps back to start remaining, negative means infinite
+	exit       bool
+	done       bool // count was zero, nothing is streamed
+	fade       int
+	curve      Curve
+	buf        [][2]float64
+	seam       [][2]float64 // crossfaded seam which needs to be streamed before continuing with s
+	err        error
+}
+
+// SetCrossfade makes the Looper crossfade the last num samples before the end of the region with
+// the first num samples of the region, following the provided curve. This hides clicks at the
+// seam, but makes every repetition of the region num samples shorter. If num is larger than the
+// region, the whole region is crossfaded.
+//
+// Use LinearCurve if the region is cut from a single continuous recording.
+//
+// SetCrossfade panics if num is negative.
+func (l *Looper) SetCrossfade(num int, curve Curve) {
+	if num < 0 {
+		panic(fmt.Errorf("loop region: invalid crossfade: %v", num))
+	}
+	l.fade = num
+	l.curve = curve
+}
+
+// Exit makes the Looper stop looping. The current repetition of the region finishes and the Looper
+// continues past its end.
+func (l *Looper) Exit() {
+	l.exit = true
+}
+
+// Stream streams the wrapped StreamSeeker, looping the region.
+func (l *Looper) Stream(samples [][2]float64) (n int, ok bool) {
+	if l.done || l.Err() != nil {
+		return 0, false
+	}
+	for len(samples) > 0 {
+		if len(l.seam) > 0 {
+			cn := copy(samples, l.seam)
+			l.seam = l.seam[cn:]
+			samples = samples[cn:]
+			n += cn
+			continue
+		}
+
+		toStream := len(samples)
+		pos := l.s.Position()
+		if !l.exit && l.remains != 0 && pos <= l.end {
+			fade := l.fade
+			if fade > l.end-l.start {
+				fade = l.end - l.start
+			}
+			if pos >= l.end-fade {
+				if err := l.jump(pos, fade); err != nil {
+					l.err = err
+					break
+				}
+				continue
+			}
+			if toStream > l.end-fade-pos {
+				toStream = l.end - fade - pos
+			}
+		}
+
+		sn, sok := l.s.Stream(samples[:toStream])
+		samples = samples[sn:]
+		n += sn
+		if !sok || sn < toStream {
+			break
+		}
+	}
+	return n, n > 0
+}
+
+// jump seeks back to the start of the region, crossfading what's left until the end (from pos)
+// with the corresponding part of the beginning of the region into the seam.
+func (l *Looper) jump(pos, fade int) error {
+	if l.remains > 0 {
+		l.remains--
+	}
+	if fade == 0 {
+		return l.s.Seek(l.start)
+	}
+
+	offset := fade - (l.end - pos)
+	if cap(l.buf) < 2*fade {
+		l.buf = make([][2]float64, 2*fade)
+	}
+	tail := l.buf[:l.end-pos]
+	head := l.buf[fade : fade+len(tail)]
+	streamFull(l.s, tail)
+	if err := l.s.Seek(l.start + offset); err != nil {
+		return err
+	}
+	streamFull(l.s, head)
+
+	for i := range tail {
+		x := float64(offset+i+1) / float64(fade+1)
+		in, out := l.curve(x), l.curve(1-x)
+		tail[i][0] = tail[i][0]*out + head[i][0]*in
+		tail[i][1] = tail[i][1]*out + head[i][1]*in
+	}
+	l.seam = tail
+	return nil
+}
+
+// Err propagates the wrapped StreamSeeker's errors, including the errors of seeking it back to
+// the start of the region.
+func (l *Looper) Err() error {
+	if l.err != nil {
+		return l.err
+	}
+	return l.s.Err()
+}
+
+// streamFull streams from s until samples are full or s is drained. The samples which didn't get
+// streamed are set to silence.
+func streamFull(s Streamer, samples [][2]float64) {
+	for len(samples) > 0 {
+		sn, sok := s.Stream(samples)
+		samples = samples[sn:]
+		if !sok || sn == 0 {
+			break
+		}
+	}
+	for i := range samples {
+		samples[i] = [2]float64{}
+	}
+}
+
 // Seq takes zero or more Streamers and returns a Streamer which streams them one by one without pauses.
 //
 // Seq does not propagate errors from the Streamers.
@@ -94,6 +394,112 @@ func Seq(s ...Streamer) Streamer {
 	})
 }
 
+// SeekableSeq is like Seq, but takes StreamSeekers and returns a Sequence, which is a
+// StreamSeeker too. Positions of the Sequence map to the positions of the individual
+// StreamSeekers, so a whole playlist or album can be seeked and its total length reported.
+//
+// Each StreamSeeker is seeked to its beginning when the Sequence gets to it, the first one right
+// away, so the StreamSeekers shouldn't be used by anything else at the same time.
+//
+// Unlike Seq, the returned Sequence propagates errors from the StreamSeekers, including an error
+// seeking the first one, which is returned by Err.
+func SeekableSeq(s ...StreamSeeker) *Sequence {
+	q := &Sequence{s: s}
+	if len(s) > 0 {
+		q.err = s[0].Seek(0)
+	}
+	return q
+}
+
+// Sequence is a StreamSeeker created by SeekableSeq.
+type Sequence struct {
+	s   []StreamSeeker
+	i   int // index of the current StreamSeeker
+	pos int
+	err error
+}
+
+// Stream streams the StreamSeekers one by one.
+func (q *Sequence) Stream(samples [][2]float64) (n int, ok bool) {
+	for q.err == nil && q.i < len(q.s) && len(samples) > 0 {
+		sn, sok := q.s[q.i].Stream(samples)
+		samples = samples[sn:]
+		n += sn
+		q.pos += sn
+		if !sok {
+			if err := q.s[q.i].Err(); err != nil {
+				q.err = err
+				break
+			}
+			q.i++
+			if q.i < len(q.s) {
+				if err := q.s[q.i].Seek(0); err != nil {
+					q.err = err
+					break
+				}
+			}
+		}
+	}
+	return n, n > 0
+}
+
+// Err propagates the errors of the StreamSeekers.
+func (q *Sequence) Err() error {
+	return q.err
+}
+
+// Len returns the sum of the lengths of the StreamSeekers.
+func (q *Sequence) Len() int {
+	length := 0
+	for _, s := range q.s {
+		length += s.Len()
+	}
+	return length
+}
+
+// Position returns the current position in the whole Sequence.
+func (q *Sequence) Position() int {
+	return q.pos
+}
+
+// Seek seeks the StreamSeeker which contains the position p to the corresponding position. A
+// successful Seek clears the error of the Sequence, so that it can recover from an error of one of
+// the StreamSeekers by seeking past it.
+func (q *Sequence) Seek(p int) error {
+	if p < 0 || q.Len() < p {
+		return fmt.Errorf("seq: seek position %v out of range [%v, %v]", p, 0, q.Len())
+	}
+	start := 0
+	for i, s := range q.s {
+		if p < start+s.Len() {
+			if err := s.Seek(p - start); err != nil {
+				return err
+			}
+			q.i, q.pos, q.err = i, p, nil
+			return nil
+		}
+		start += s.Len()
+	}
+	// seeking to the very end
+	q.i, q.pos, q.err = len(q.s), p, nil
+	return nil
+}
+
+// Current returns the index of the StreamSeeker currently being streamed. When the Sequence is
+// drained, Current returns the number of StreamSeekers.
+func (q *Sequence) Current() int {
+	return q.i
+}
+
+// Offset returns the position in the Sequence at which the i-th StreamSeeker starts.
+func (q *Sequence) Offset(i int) int {
+	start := 0
+	for _, s := range q.s[:i] {
+		start += s.Len()
+	}
+	return start
+}
+
 // Mix takes zero or more Streamers and returns a Streamer which streams them mixed together.
 //
 // Mix does not propagate errors from the Streamers.
@@ -140,6 +546,9 @@ func Mix(s ...Streamer) Streamer {
 
 // Dup returns two Streamers which both stream the same data as the original s. The two Streamers
 // can't be used concurrently without synchronization.
+//
+// Dup buffers all the samples one of the Streamers got ahead of the other one. Use Tee for more
+// than two Streamers, concurrent use, or bounded buffering.
 func Dup(s Streamer) (t, u Streamer) {
 	var tBuf, uBuf [][2]float64
 	return &dup{&tBuf, &uBuf, s}, &dup{&uBuf, &tBuf, s}
@@ -1,6 +1,8 @@
 package beep_test
 
 import (
+	"errors"
+	"math"
 	"math/rand"
 	"reflect"
 	"testing"
@@ -177,3 +179,365 @@ func TestDup(t *testing.T) {
 		}
 	}
 }
+
+func TestTransition(t *testing.T) {
+	a, aData := randomDataStreamer(1000)
+	b, bData := randomDataStreamer(1500)
+
+	got := collect(beep.Transition(beep.LinearCurve, 500, a, b))
+	if len(got) != len(bData) {
+		t.Fatalf("Transition streamed %d samples, expected %d", len(got), len(bData))
+	}
+	for i := range got {
+		want := bData[i]
+		if i < 500 {
+			x := float64(i) / 500
+			want[0] = aData[i][0]*(1-x) + bData[i][0]*x
+			want[1] = aData[i][1]*(1-x) + bData[i][1]*x
+		}
+		if math.Abs(got[i][0]-want[0]) > 1e-9 || math.Abs(got[i][1]-want[1]) > 1e-9 {
+			t.Fatalf("Transition sample %d: expected: %v, actual: %v", i, want, got[i])
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Transition didn't panic on a negative number of samples")
+		}
+	}()
+	beep.Transition(beep.LinearCurve, -1, a, b)
+}
+
+func TestCrossfade(t *testing.T) {
+	var (
+		n    = 5
+		fade = 300
+		s    = make([]beep.Streamer, n)
+		data = make([][][2]float64, n)
+		want int
+	)
+	for i := range s {
+		s[i], data[i] = randomDataStreamer(rand.Intn(1e4) + 1e3)
+		want += len(data[i])
+	}
+	want -= (n - 1) * fade
+
+	got := collect(beep.Crossfade(beep.EqualPowerCurve, fade, s...))
+	if len(got) != want {
+		t.Fatalf("Crossfade streamed %d samples, expected %d", len(got), want)
+	}
+	if !reflect.DeepEqual(data[0][:len(data[0])-fade], got[:len(data[0])-fade]) {
+		t.Error("Crossfade changed samples outside of the crossfade")
+	}
+	last := data[n-1][fade:]
+	if !reflect.DeepEqual(last, got[len(got)-len(last):]) {
+		t.Error("Crossfade changed samples outside of the crossfade")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Crossfade didn't panic on a negative number of samples")
+		}
+	}()
+	beep.Crossfade(beep.LinearCurve, -1, s...)
+}
+
+func TestCtrlFade(t *testing.T) {
+	s, data := randomDataStreamer(1000)
+	ctrl := &beep.Ctrl{Streamer: s, Fade: 100}
+
+	buf := make([][2]float64, 200)
+	ctrl.Stream(buf)
+	if !reflect.DeepEqual(data[:200], buf) {
+		t.Fatal("Ctrl changed samples before pausing")
+	}
+
+	ctrl.Paused = true
+	ctrl.Stream(buf)
+	for i := range buf {
+		gain := 1 - float64(i+1)/100
+		if i >= 100 {
+			gain = 0
+		}
+		if math.Abs(buf[i][0]-data[200+i][0]*gain) > 1e-9 {
+			t.Fatalf("Ctrl didn't fade out correctly at sample %d", i)
+		}
+	}
+
+	ctrl.Paused = false
+	ctrl.Stream(buf)
+	if math.Abs(buf[0][0]-data[300][0]*0.01) > 1e-9 || !reflect.DeepEqual(data[400:500], buf[100:]) {
+		t.Fatal("Ctrl didn't resume where the fade-out ended")
+	}
+
+	ctrl.Stop()
+	n, ok := ctrl.Stream(buf)
+	if n != 100 || !ok || ctrl.Streamer != nil {
+		t.Fatalf("Ctrl didn't stop after fading out: n=%d, ok=%v", n, ok)
+	}
+	if n, ok := ctrl.Stream(buf); n != 0 || ok {
+		t.Fatal("stopped Ctrl isn't drained")
+	}
+}
+
+func TestLoopRegion(t *testing.T) {
+	for _, fade := range []int{0, 100} {
+		s, data := randomDataStreamer(5000)
+		start, end := 1000, 3000
+
+		looper := beep.LoopRegion(3, start, end, s)
+		looper.SetCrossfade(fade, beep.LinearCurve)
+		got := collect(looper)
+
+		want := len(data) + 2*(end-start-fade)
+		if len(got) != want {
+			t.Fatalf("LoopRegion streamed %d samples, expected %d (fade: %d)", len(got), want, fade)
+		}
+		if !reflect.DeepEqual(data[:end-fade], got[:end-fade]) {
+			t.Fatalf("LoopRegion intro not correct (fade: %d)", fade)
+		}
+		if !reflect.DeepEqual(data[start+fade:], got[len(got)-len(data[start+fade:]):]) {
+			t.Fatalf("LoopRegion ending not correct (fade: %d)", fade)
+		}
+	}
+
+	s, data := randomDataStreamer(5000)
+	looper := beep.LoopRegion(-1, 1000, 3000, s)
+	buf := make([][2]float64, 10000)
+	looper.Stream(buf)
+	looper.Exit()
+	got := collect(looper)
+	if !reflect.DeepEqual(data[2000:], got) {
+		t.Fatal("LoopRegion didn't exit correctly")
+	}
+
+	s, _ = randomDataStreamer(5000)
+	if got := collect(beep.LoopRegion(0, 1000, 3000, s)); len(got) != 0 {
+		t.Fatalf("LoopRegion with count 0 streamed %d samples, expected 0", len(got))
+	}
+
+	for _, region := range [][2]int{{-1, 100}, {100, 100}, {200, 100}, {1000, 5001}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("LoopRegion didn't panic on invalid region %v", region)
+				}
+			}()
+			beep.LoopRegion(-1, region[0], region[1], s)
+		}()
+	}
+}
+
+// seekErrorStreamer is a StreamSeeker which fails to seek.
+type seekErrorStreamer struct {
+	beep.StreamSeeker
+	err error
+}
+
+func (ses *seekErrorStreamer) Seek(p int) error {
+	return ses.err
+}
+
+func TestLoopRegionSeekError(t *testing.T) {
+	for _, fade := range []int{0, 100} {
+		s, data := randomDataStreamer(5000)
+		seekErr := errors.New("seek error")
+		looper := beep.LoopRegion(3, 1000, 3000, &seekErrorStreamer{s, seekErr})
+		looper.SetCrossfade(fade, beep.LinearCurve)
+		got := collect(looper)
+		if !reflect.DeepEqual(data[:3000-fade], got) {
+			t.Errorf("LoopRegion streamed %d samples before the seek error, expected %d (fade: %d)", len(got), 3000-fade, fade)
+		}
+		if looper.Err() != seekErr {
+			t.Errorf("LoopRegion error %v, expected %v (fade: %d)", looper.Err(), seekErr, fade)
+		}
+		if n, ok := looper.Stream(make([][2]float64, 100)); n != 0 || ok {
+			t.Errorf("LoopRegion streamed (%d, %v) after the seek error, expected (0, false)", n, ok)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("SetCrossfade didn't panic on a negative crossfade")
+		}
+	}()
+	s, _ := randomDataStreamer(5000)
+	beep.LoopRegion(-1, 1000, 3000, s).SetCrossfade(-1, beep.LinearCurve)
+}
+
+func TestReverse(t *testing.T) {
+	s, data := randomDataStreamer(rand.Intn(1e5) + 1e4)
+
+	want := make([][2]float64, len(data))
+	for i := range data {
+		want[len(data)-1-i] = data[i]
+	}
+
+	r := beep.Reverse(s)
+	if got := collect(r); !reflect.DeepEqual(want, got) {
+		t.Fatal("Reverse not working correctly")
+	}
+
+	if err := r.Seek(1234); err != nil {
+		t.Fatal(err)
+	}
+	if got := collect(r); !reflect.DeepEqual(want[1234:], got) {
+		t.Fatal("Reverse not seeking correctly")
+	}
+}
+
+func TestSeekableSeq(t *testing.T) {
+	var (
+		s    []beep.StreamSeeker
+		data [][2]float64
+	)
+	for _, n := range []int{1000, 0, 2345, 777} {
+		ss, d := randomDataStreamer(n)
+		s = append(s, ss)
+		data = append(data, d...)
+	}
+	seq := beep.SeekableSeq(s...)
+
+	if seq.Len() != len(data) {
+		t.Fatalf("SeekableSeq length %d, expected %d", seq.Len(), len(data))
+	}
+	if got := collect(seq); !reflect.DeepEqual(data, got) {
+		t.Fatal("SeekableSeq not streaming correctly")
+	}
+
+	for _, pos := range []int{1500, 0, 1000, 3345, len(data)} {
+		if err := seq.Seek(pos); err != nil {
+			t.Fatal(err)
+		}
+		if seq.Position() != pos {
+			t.Fatalf("SeekableSeq position %d after seeking to %d", seq.Position(), pos)
+		}
+		if got := collect(seq); len(got) != len(data)-pos || len(got) > 0 && !reflect.DeepEqual(data[pos:], got) {
+			t.Fatalf("SeekableSeq not streaming correctly after seeking to %d", pos)
+		}
+	}
+
+	seq.Seek(3400)
+	if seq.Current() != 3 || seq.Offset(3) != 3345 {
+		t.Fatalf("SeekableSeq current item %d at %d, expected 3 at 3345", seq.Current(), seq.Offset(3))
+	}
+
+	// the first StreamSeeker starts from its beginning even if it was streamed before
+	s[0].Seek(500)
+	seq = beep.SeekableSeq(s...)
+	if got := collect(seq); !reflect.DeepEqual(data, got) {
+		t.Fatal("SeekableSeq not streaming the first StreamSeeker from its beginning")
+	}
+}
+
+func TestSeekableSeqError(t *testing.T) {
+	s1, data1 := randomDataStreamer(1000)
+	s2, _ := randomDataStreamer(1000)
+	s3, data3 := randomDataStreamer(500)
+	seekErr := errors.New("seek error")
+	seq := beep.SeekableSeq(s1, &seekErrorStreamer{s2, seekErr}, s3)
+
+	if got := collect(seq); !reflect.DeepEqual(data1, got) || seq.Err() != seekErr {
+		t.Fatalf("SeekableSeq streamed %d samples with error %v, expected %d with %v", len(got), seq.Err(), len(data1), seekErr)
+	}
+	if err := seq.Seek(1500); err != seekErr || seq.Err() != seekErr {
+		t.Fatalf("SeekableSeq failed seek returned %v with error %v, expected %v", err, seq.Err(), seekErr)
+	}
+
+	// seeking past the failing StreamSeeker recovers
+	if err := seq.Seek(2000); err != nil || seq.Err() != nil {
+		t.Fatalf("SeekableSeq seek returned %v with error %v, expected no error", err, seq.Err())
+	}
+	if got := collect(seq); !reflect.DeepEqual(data3, got) {
+		t.Fatal("SeekableSeq not streaming correctly after recovering from an error")
+	}
+	if err := seq.Seek(0); err != nil || seq.Err() != nil {
+		t.Fatalf("SeekableSeq seek returned %v with error %v, expected no error", err, seq.Err())
+	}
+}
+
+func TestSlice(t *testing.T) {
+	s, data := randomDataStreamer(5000)
+
+	// two Slices of the same StreamSeeker, streamed out of order
+	a, b := beep.Slice(3000, 4500, s), beep.Slice(500, 1000, s)
+	want := append(append([][2]float64{}, data[3000:4500]...), data[500:1000]...)
+	if got := collect(beep.Seq(a, b)); !reflect.DeepEqual(want, got) {
+		t.Fatal("Slice not streaming correctly")
+	}
+
+	if a.Len() != 1500 || a.Position() != 1500 {
+		t.Fatalf("Slice length %d and position %d, expected 1500 and 1500", a.Len(), a.Position())
+	}
+	if err := a.Seek(1000); err != nil {
+		t.Fatal(err)
+	}
+	if got := collect(a); !reflect.DeepEqual(data[4000:4500], got) {
+		t.Fatal("Slice not streaming correctly after seeking")
+	}
+}
+
+func TestTakeSeek(t *testing.T) {
+	s, data := randomDataStreamer(5000)
+	s.Seek(1000)
+
+	take, ok := beep.Take(3000, s).(beep.StreamSeeker)
+	if !ok {
+		t.Fatal("Take of a StreamSeeker is not a StreamSeeker")
+	}
+	if take.Len() != 3000 {
+		t.Fatalf("Take length %d, expected 3000", take.Len())
+	}
+	take.Seek(2000)
+	if got := collect(take); !reflect.DeepEqual(data[3000:4000], got) {
+		t.Fatal("Take not streaming correctly after seeking")
+	}
+
+	silence, ok := beep.Silence(1000).(beep.StreamSeeker)
+	if !ok {
+		t.Fatal("Silence is not a StreamSeeker")
+	}
+	silence.Seek(400)
+	if got := collect(silence); len(got) != 600 || got[0] != [2]float64{} {
+		t.Fatal("Silence not streaming correctly after seeking")
+	}
+}
+
+func TestTakeConsecutive(t *testing.T) {
+	// consecutive Takes of the same StreamSeeker stream consecutive parts of it
+	s, data := randomDataStreamer(1000)
+	got := collect(beep.Seq(beep.Take(100, s), beep.Take(100, s), beep.Take(100, s)))
+	if !reflect.DeepEqual(data[:300], got) {
+		t.Fatal("consecutive Takes not streaming consecutive parts")
+	}
+	if s.Position() != 300 {
+		t.Fatalf("StreamSeeker at position %d after consecutive Takes, expected 300", s.Position())
+	}
+}
+
+func TestTakeNonSeekable(t *testing.T) {
+	// Resampler, SincResampler and Prefetcher are StreamSeekers with zero length when their
+	// source can't seek
+	generator := func() beep.Streamer {
+		return beep.StreamerFunc(func(samples [][2]float64) (n int, ok bool) {
+			for i := range samples {
+				samples[i] = [2]float64{0.5, 0.5}
+			}
+			return len(samples), true
+		})
+	}
+	for name, s := range map[string]beep.Streamer{
+		"Resample":     beep.Resample(3, 44100, 48000, generator()),
+		"ResampleSinc": beep.ResampleSinc(beep.SincFast, 44100, 48000, generator()),
+		"Prefetch":     beep.Prefetch(512, generator()),
+	} {
+		if got := collect(beep.Take(1000, s)); len(got) != 1000 {
+			t.Errorf("Take of %s streamed %d samples, expected 1000", name, len(got))
+		}
+	}
+
+	s, data := randomDataStreamer(500)
+	if got := collect(beep.Take(1000, s)); !reflect.DeepEqual(data, got) {
+		t.Error("Take longer than its StreamSeeker not streaming correctly")
+	}
+}
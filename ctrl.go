@@ -1,5 +1,7 @@
 package beep
 
+import "math"
+
 // Ctrl allows for pausing a Streamer.
 //
 // Wrap a Streamer in a Ctrl.
@@ -15,6 +17,14 @@ package beep
 //
 //   ctrl.Streamer = nil
 //
+// Pausing, resuming and stopping like this happens instantly, which may produce audible clicks.
+// To avoid them, set the Fade field to the number of samples over which the change should be
+// ramped and use the Stop method for stopping.
+//
+//   ctrl := &beep.Ctrl{Streamer: s, Fade: sr.N(time.Second / 50)}
+//   // ...
+//   ctrl.Stop()
+//
 // If you're playing a Streamer wrapped in a Ctrl through the speaker, you need to lock and unlock
 // the speaker when modifying the Ctrl to avoid race conditions.
 //
@@ -26,6 +36,21 @@ package beep
 type Ctrl struct {
 	Streamer Streamer
 	Paused   bool
+	Fade     int
+
+	started  bool
+	level    float64 // current gain, ramps towards 0 or 1
+	stopping bool
+}
+
+// Stop stops the Ctrl. If Fade is positive, the wrapped Streamer fades out first, otherwise it
+// gets stopped instantly. Either way, the wrapped Streamer is set to nil once it's stopped.
+func (c *Ctrl) Stop() {
+	if c.Fade <= 0 {
+		c.Streamer = nil
+		return
+	}
+	c.stopping = true
 }
 
 // Stream streams the wrapped Streamer, if not nil. If the Streamer is nil, Ctrl acts as drained.
@@ -34,13 +59,66 @@ func (c *Ctrl) Stream(samples [][2]float64) (n int, ok bool) {
 	if c.Streamer == nil {
 		return 0, false
 	}
-	if c.Paused {
+
+	target := 1.0
+	if c.Paused || c.stopping {
+		target = 0
+	}
+	if !c.started || c.Fade <= 0 {
+		c.started = true
+		c.level = target
+	}
+
+	if c.level == target {
+		if target == 1 {
+			return c.Streamer.Stream(samples)
+		}
+		if c.stopping {
+			c.Streamer = nil
+			c.stopping = false
+			return 0, false
+		}
 		for i := range samples {
 			samples[i] = [2]float64{}
 		}
 		return len(samples), true
 	}
-	return c.Streamer.Stream(samples)
+
+	// ramp towards the target, only stream as much as the ramp needs when fading out
+	step := 1 / float64(c.Fade)
+	ramp := int(math.Ceil(math.Abs(target-c.level) / step))
+	toStream := len(samples)
+	if target == 0 && ramp < toStream {
+		toStream = ramp
+	}
+	n, ok = c.Streamer.Stream(samples[:toStream])
+	for i := range samples[:n] {
+		if i+1 >= ramp {
+			c.level = target
+		} else if target > c.level {
+			c.level += step
+		} else {
+			c.level -= step
+		}
+		samples[i][0] *= c.level
+		samples[i][1] *= c.level
+	}
+	if !ok || n < toStream {
+		return n, ok
+	}
+
+	if c.level == 0 {
+		if c.stopping {
+			c.Streamer = nil
+			c.stopping = false
+			return n, true
+		}
+		for i := range samples[n:] {
+			samples[n+i] = [2]float64{}
+		}
+		return len(samples), true
+	}
+	return n, ok
 }
 
 // Err returns the error of the wrapped Streamer, if not nil.
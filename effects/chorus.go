@@ -0,0 +1,75 @@
+package effects
+
+import (
+	"math"
+	"time"
+
+	"github.com/faiface/beep"
+)
+
+// chorusMaxDelay is the longest delay of a Chorus.
+const chorusMaxDelay = 100 * time.Millisecond
+
+// NewChorus returns a Chorus which thickens s by mixing it with a copy of itself delayed by a
+// slowly varying time. The sample rate sr must match the sample rate of s. The Chorus starts at a
+// delay of 20 ms varying by 2 ms with a 0.8 Hz sine LFO, with the channels a quarter of a cycle
+// apart.
+//
+//   chorus := effects.NewChorus(format.SampleRate, streamer)
+//   chorus.LFO.Shape = effects.LFORandom
+//   speaker.Play(chorus)
+//
+// The returned Chorus propagates s's errors.
+func NewChorus(sr beep.SampleRate, s beep.Streamer) *Chorus {
+	size := sr.N(chorusMaxDelay) + 4
+	return &Chorus{
+		LFO:   LFO{Rate: 0.8, Depth: float64(sr.N(2 * time.Millisecond)), Phase: 0.25},
+		Delay: float64(sr.N(20 * time.Millisecond)),
+		Wet:   0.5,
+		Dry:   1,
+		sr:    sr,
+		tail:  tail{s: s},
+		lines: [2]delayLine{newDelayLine(size), newDelayLine(size)},
+	}
+}
+
+// Chorus is a chorus effect created by NewChorus.
+//
+// If you're playing the Chorus through the speaker, you need to lock and unlock the speaker when
+// changing its parameters.
+type Chorus struct {
+	// LFO varies the delay, its Depth is in samples.
+	LFO LFO
+
+	// Delay is the delay in samples around which the LFO varies it, up to 100 ms.
+	Delay float64
+
+	// Wet and Dry are the gains of the delayed copy and of the original audio.
+	Wet, Dry float64
+
+	sr    beep.SampleRate
+	tail  tail
+	lines [2]delayLine
+}
+
+// Stream streams the wrapped Streamer through the chorus.
+func (c *Chorus) Stream(samples [][2]float64) (n int, ok bool) {
+	return c.tail.stream(samples, int(c.Delay+math.Abs(c.LFO.Depth))+3, c.process)
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (c *Chorus) Err() error {
+	return c.tail.err()
+}
+
+func (c *Chorus) process(samples [][2]float64) {
+	for i := range samples {
+		v := c.LFO.next(c.sr)
+		for ch := range v {
+			x := samples[i][ch]
+			y := c.lines[ch].read(c.Delay + v[ch])
+			c.lines[ch].write(x)
+			samples[i][ch] = x*c.Dry + y*c.Wet
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package effects
+
+import (
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/fft"
+)
+
+// convolverBlock is the partition size of the convolution. The Convolver reads this many samples
+// of the wrapped Streamer ahead.
+const convolverBlock = 512
+
+// ImpulseResponse is an impulse response for a Convolver, such as a recording of a room.
+type ImpulseResponse struct {
+	samples [][2]float64
+}
+
+// LoadImpulseResponse reads the whole s as an impulse response. It can be any Streamer, for
+// example a decoded WAV file. The sample rate of the impulse response must match the sample rate
+// of the audio it will be used with, use beep.Resample otherwise.
+//
+//   f, err := os.Open("hall.wav")
+//   // ...
+//   s, format, err := wav.Decode(f)
+//   // ...
+//   ir, err := effects.LoadImpulseResponse(s)
+//
+// The left channel of the impulse response applies to the left channel of the audio and the right
+// one to the right one. Mono files decode to the same left and right channels.
+//
+// LoadImpulseResponse returns s's error, if any.
+func LoadImpulseResponse(s beep.Streamer) (*ImpulseResponse, error) {
+	ir := &ImpulseResponse{}
+	var buf [512][2]float64
+	for {
+		n, ok := s.Stream(buf[:])
+		ir.samples = append(ir.samples, buf[:n]...)
+		if !ok {
+			break
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return ir, nil
+}
+
+// Len returns the number of samples of the impulse response.
+func (ir *ImpulseResponse) Len() int {
+	return len(ir.samples)
+}
+
+// NewConvolver returns a Convolver which convolves s with the impulse response ir, for example to
+// place s in the room where the impulse response was recorded.
+//
+// The convolution uses uniformly partitioned FFT convolution, so its cost doesn't grow with the
+// length of the impulse response as quickly as direct convolution and impulse responses several
+// seconds long are usable for live playback. The output is aligned with s, there is no delay.
+//
+// The Convolver streams the whole tail of the impulse response after s is drained.
+//
+// The returned Convolver propagates s's errors.
+func NewConvolver(ir *ImpulseResponse, s beep.Streamer) *Convolver {
+	const bins = convolverBlock + 1
+	c := &Convolver{
+		Wet:      1,
+		s:        s,
+		plan:     fft.NewPlan(2 * convolverBlock),
+		total:    -1,
+		frame:    make([]float64, 2*convolverBlock),
+		spectrum: make([]complex128, bins),
+	}
+
+	// transform each partition of the impulse response, zero padded to the FFT size
+	partitions := (len(ir.samples) + convolverBlock - 1) / convolverBlock
+	if partitions < 1 {
+		partitions = 1
+	}
+	c.tail = len(ir.samples) - 1
+	if c.tail < 0 {
+		c.tail = 0
+	}
+	for ch := range c.h {
+		c.h[ch] = make([][]complex128, partitions)
+		c.fdl[ch] = make([][]complex128, partitions)
+		for p := range c.h[ch] {
+			for i := range c.frame {
+				c.frame[i] = 0
+				if i < convolverBlock && p*convolverBlock+i < len(ir.samples) {
+					c.frame[i] = ir.samples[p*convolverBlock+i][ch]
+				}
+			}
+			c.h[ch][p] = make([]complex128, bins)
+			c.plan.Real(c.h[ch][p], c.frame)
+			c.fdl[ch][p] = make([]complex128, bins)
+		}
+		c.prev[ch] = make([]float64, convolverBlock)
+	}
+	return c
+}
+
+// Convolver is a Streamer created by NewConvolver. Wet is the gain of the convolved audio and Dry
+// is the gain of the original audio, by default 1 and 0. Both can be changed while streaming.
+type Convolver struct {
+	Wet, Dry float64
+
+	s    beep.Streamer
+	plan *fft.Plan
+	tail int // length of the tail after s is drained
+
+	h    [2][][]complex128 // spectra of the partitions of the impulse response
+	fdl  [2][][]complex128 // spectra of the last input blocks, fdl[ch][pos] is the latest
+	pos  int
+	prev [2][]float64 // previous input block
+
+	block    [convolverBlock][2]float64 // current input block
+	frame    []float64
+	spectrum []complex128
+
+	out    [][2]float64 // finished samples waiting to be streamed
+	buf    [convolverBlock][2]float64
+	outPos int // number of samples produced so far
+	total  int // total number of samples to produce, or -1 if s isn't drained yet
+}
+
+// Stream streams the wrapped Streamer convolved with the impulse response.
+func (c *Convolver) Stream(samples [][2]float64) (n int, ok bool) {
+	for n < len(samples) {
+		if len(c.out) == 0 {
+			if !c.process() {
+				break
+			}
+			continue
+		}
+		k := copy(samples[n:], c.out)
+		c.out = c.out[k:]
+		n += k
+	}
+	return n, n > 0
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (c *Convolver) Err() error {
+	return c.s.Err()
+}
+
+// process convolves the next block of the input. It returns false when the tail is finished.
+func (c *Convolver) process() bool {
+	if c.total >= 0 && c.outPos >= c.total {
+		return false
+	}
+
+	// read the next input block, silence after the end
+	read := 0
+	for c.total < 0 && read < convolverBlock {
+		sn, sok := c.s.Stream(c.block[read:])
+		read += sn
+		if !sok {
+			// after an error, the samples read before it are still streamed, but not the tail
+			c.total = c.outPos + read
+			if c.s.Err() == nil {
+				c.total += c.tail
+			}
+		}
+	}
+	for i := range c.block[read:] {
+		c.block[read+i] = [2]float64{}
+	}
+
+	c.pos = (c.pos + 1) % len(c.fdl[0])
+	for ch := range c.fdl {
+		// overlap-save: transform the previous and the current block together
+		copy(c.frame, c.prev[ch])
+		for i := range c.block {
+			c.frame[convolverBlock+i] = c.block[i][ch]
+			c.prev[ch][i] = c.block[i][ch]
+		}
+		c.plan.Real(c.fdl[ch][c.pos], c.frame)
+
+		// multiply each partition with the input block delayed by its index
+		for k := range c.spectrum {
+			c.spectrum[k] = 0
+		}
+		for p, h := range c.h[ch] {
+			x := c.fdl[ch][(c.pos-p+len(c.fdl[ch]))%len(c.fdl[ch])]
+			for k := range c.spectrum {
+				c.spectrum[k] += x[k] * h[k]
+			}
+		}
+		c.plan.RealInverse(c.frame, c.spectrum)
+
+		for i := range c.buf {
+			c.buf[i][ch] = c.Dry*c.block[i][ch] + c.Wet*c.frame[convolverBlock+i]
+		}
+	}
+
+	n := convolverBlock
+	if c.total >= 0 && c.total-c.outPos < n {
+		n = c.total - c.outPos
+	}
+	c.out = c.buf[:n]
+	c.outPos += n
+	return true
+}
@@ -0,0 +1,108 @@
+package effects_test
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/faiface/beep/effects"
+)
+
+// dataStreamer streams the data and then fails with err, if it's not nil.
+type dataStreamer struct {
+	data [][2]float64
+	err  error
+}
+
+func (ds *dataStreamer) Stream(samples [][2]float64) (n int, ok bool) {
+	if len(ds.data) == 0 {
+		return 0, false
+	}
+	n = copy(samples, ds.data)
+	ds.data = ds.data[n:]
+	return n, true
+}
+
+func (ds *dataStreamer) Err() error {
+	if len(ds.data) == 0 {
+		return ds.err
+	}
+	return nil
+}
+
+func randomData(numSamples int) [][2]float64 {
+	data := make([][2]float64, numSamples)
+	for i := range data {
+		data[i] = [2]float64{rand.Float64()*2 - 1, rand.Float64()*2 - 1}
+	}
+	return data
+}
+
+func TestConvolver(t *testing.T) {
+	for _, lengths := range [][2]int{{3000, 700}, {100, 1}, {1, 2000}, {1024, 512}} {
+		data, irData := randomData(lengths[0]), randomData(lengths[1])
+		ir, err := effects.LoadImpulseResponse(&dataStreamer{data: irData})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		conv := effects.NewConvolver(ir, &dataStreamer{data: data})
+		conv.Dry = 0.5
+		got := collect(conv)
+		if want := len(data) + len(irData) - 1; len(got) != want {
+			t.Fatalf("Convolver streamed %d samples, expected %d", len(got), want)
+		}
+		for i := range got {
+			for c := range got[i] {
+				// direct convolution
+				var want float64
+				for j := range irData {
+					if k := i - j; 0 <= k && k < len(data) {
+						want += data[k][c] * irData[j][c]
+					}
+				}
+				if i < len(data) {
+					want += 0.5 * data[i][c]
+				}
+				if math.Abs(got[i][c]-want) > 1e-9 {
+					t.Fatalf("Convolver sample %d is %v, expected %v", i, got[i][c], want)
+				}
+			}
+		}
+	}
+}
+
+func TestConvolverError(t *testing.T) {
+	ir, _ := effects.LoadImpulseResponse(&dataStreamer{data: randomData(2000)})
+	data := randomData(700)
+	want := collect(effects.NewConvolver(ir, &dataStreamer{data: data}))[:len(data)]
+
+	// the samples before the error are streamed, including the partial last block
+	conv := effects.NewConvolver(ir, &dataStreamer{data: data, err: errors.New("broken")})
+	var (
+		buf [512][2]float64
+		got [][2]float64
+	)
+	for {
+		n, ok := conv.Stream(buf[:])
+		got = append(got, buf[:n]...)
+		if !ok {
+			break
+		}
+	}
+	if conv.Err() == nil {
+		t.Fatal("Convolver didn't propagate the error")
+	}
+	if len(got) != len(data) {
+		t.Fatalf("Convolver streamed %d samples before the error of a %d samples long Streamer", len(got), len(data))
+	}
+	for i := range got {
+		if math.Abs(got[i][0]-want[i][0]) > 1e-9 || math.Abs(got[i][1]-want[i][1]) > 1e-9 {
+			t.Fatalf("Convolver sample %d before the error: expected: %v, actual: %v", i, want[i], got[i])
+		}
+	}
+	if n, ok := conv.Stream(buf[:]); n != 0 || ok {
+		t.Fatal("Convolver keeps streaming after an error")
+	}
+}
@@ -0,0 +1,186 @@
+package effects
+
+import (
+	"math"
+	"time"
+
+	"github.com/faiface/beep"
+)
+
+// DelayMode selects how a Delay routes the channels.
+type DelayMode int
+
+// Modes of Delay.
+//
+//   mode          | routing
+//   --------------|--------
+//   DelayMono     | the mono mix goes through one delay line, the echoes are in the center
+//   DelayStereo   | the channels go through separate delay lines, with separate delay times
+//   DelayPingPong | the mono mix bounces between the left and the right channel
+const (
+	DelayMono DelayMode = iota
+	DelayStereo
+	DelayPingPong
+)
+
+// NoteDelay returns the length in samples of a note value at the tempo bpm (quarter notes per
+// minute). The note is a fraction of a whole note, for example 1.0/8 for an eighth note or 3.0/16
+// for a dotted eighth note.
+//
+//   delay.Time = effects.NoteDelay(sr, 120, 3.0/16)
+func NoteDelay(sr beep.SampleRate, bpm, note float64) float64 {
+	return note * 4 * 60 / bpm * float64(sr)
+}
+
+// NewDelay returns a Delay which adds echoes of s. The sample rate sr must match the sample rate of
+// s and maxTime is the longest delay time the Delay will be used with. The delay time starts at
+// maxTime, with a feedback of 0.4 and the echoes mixed under the original audio.
+//
+//   delay := effects.NewDelay(format.SampleRate, time.Second, streamer)
+//   delay.Mode = effects.DelayPingPong
+//   delay.Time = float64(format.SampleRate.N(300 * time.Millisecond))
+//   speaker.Play(delay)
+//
+// The Delay keeps streaming the echoes after s is drained, until they fade out.
+//
+// The returned Delay propagates s's errors.
+func NewDelay(sr beep.SampleRate, maxTime time.Duration, s beep.Streamer) *Delay {
+	size := sr.N(maxTime) + 4
+	return &Delay{
+		Time:     float64(sr.N(maxTime)),
+		Feedback: 0.4,
+		Wet:      0.5,
+		Dry:      1,
+		sr:       sr,
+		tail:     tail{s: s},
+		lines:    [2]delayLine{newDelayLine(size), newDelayLine(size)},
+	}
+}
+
+// Delay is a delay effect created by NewDelay. All its parameters can be changed while streaming.
+// If you're playing the Delay through the speaker, you need to lock and unlock the speaker when
+// changing them.
+type Delay struct {
+	Mode DelayMode
+
+	// Time is the delay time in samples, it can be fractional. In DelayStereo mode, Time is the
+	// delay time of the left channel and TimeRight of the right channel, unless TimeRight is zero.
+	// Use beep.SampleRate.N for durations and NoteDelay for note values.
+	Time, TimeRight float64
+
+	// Feedback is the gain of the echoes fed back into the delay, from 0 (a single echo) to just
+	// below 1 (echoes fading out very slowly).
+	Feedback float64
+
+	// Cutoff is the cutoff frequency in Hz of a lowpass filter in the feedback path, which makes
+	// each echo darker than the previous one. Zero turns the filter off.
+	Cutoff float64
+
+	// ModRate and ModDepth modulate the delay time with a sine wave of the frequency ModRate in Hz,
+	// varying by ModDepth samples in both directions. This gives the echoes a tape-like wobble.
+	ModRate, ModDepth float64
+
+	// Wet and Dry are the gains of the echoes and of the original audio.
+	Wet, Dry float64
+
+	sr      beep.SampleRate
+	tail    tail
+	lines   [2]delayLine
+	lowpass [2]float64 // state of the feedback lowpass filters
+	mod     LFO
+}
+
+// Stream streams the wrapped Streamer with the echoes.
+func (d *Delay) Stream(samples [][2]float64) (n int, ok bool) {
+	delay := math.Max(d.Time, d.TimeRight) + math.Abs(d.ModDepth)
+	return d.tail.stream(samples, int(delay)+3, d.process)
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (d *Delay) Err() error {
+	return d.tail.err()
+}
+
+func (d *Delay) process(samples [][2]float64) {
+	timeRight := d.TimeRight
+	if timeRight == 0 {
+		timeRight = d.Time
+	}
+	lowpass := 1.0
+	if d.Cutoff > 0 {
+		lowpass = 1 - math.Exp(-2*math.Pi*d.Cutoff/float64(d.sr))
+	}
+	d.mod.Rate, d.mod.Depth = d.ModRate, d.ModDepth
+
+	for i, x := range samples {
+		mod := d.mod.next(d.sr)[0]
+
+		var y [2]float64
+		switch d.Mode {
+		case DelayMono:
+			y[0] = d.lines[0].read(d.Time + mod)
+			y[1] = y[0]
+			d.lines[0].write((x[0]+x[1])/2 + d.feedback(0, y[0], lowpass))
+		case DelayStereo:
+			y[0] = d.lines[0].read(d.Time + mod)
+			y[1] = d.lines[1].read(timeRight + mod)
+			d.lines[0].write(x[0] + d.feedback(0, y[0], lowpass))
+			d.lines[1].write(x[1] + d.feedback(1, y[1], lowpass))
+		case DelayPingPong:
+			y[0] = d.lines[0].read(d.Time + mod)
+			y[1] = d.lines[1].read(d.Time + mod)
+			d.lines[0].write((x[0]+x[1])/2 + d.feedback(1, y[1], lowpass))
+			d.lines[1].write(d.feedback(0, y[0], lowpass))
+		}
+		samples[i][0] = x[0]*d.Dry + y[0]*d.Wet
+		samples[i][1] = x[1]*d.Dry + y[1]*d.Wet
+	}
+}
+
+// feedback returns the output y of the delay line c filtered and attenuated for feeding back.
+func (d *Delay) feedback(c int, y, lowpass float64) float64 {
+	d.lowpass[c] += lowpass * (y - d.lowpass[c])
+	return d.lowpass[c] * d.Feedback
+}
+
+// delayLine is a circular buffer of past samples of one channel, which can be read at fractional
+// delays.
+type delayLine struct {
+	buf []float64
+	i   int // index of the next sample to write
+}
+
+func newDelayLine(size int) delayLine {
+	return delayLine{buf: make([]float64, size)}
+}
+
+func (l *delayLine) write(x float64) {
+	l.buf[l.i] = x
+	l.i = (l.i + 1) % len(l.buf)
+}
+
+// read returns the sample written delay samples ago, interpolated by a cubic Hermite spline. The
+// delay is clamped to the length of the line.
+func (l *delayLine) read(delay float64) float64 {
+	if delay < 1 {
+		delay = 1
+	}
+	if max := float64(len(l.buf) - 3); delay > max {
+		delay = max
+	}
+	k := int(delay)
+	t := delay - float64(k)
+	at := func(d int) float64 {
+		return l.buf[(l.i-d+len(l.buf))%len(l.buf)]
+	}
+	// the samples around the delay, y0 is the newer one of the two the delay is between
+	y0, y1, y2 := at(k), at(k+1), at(k+2)
+	ym := y0
+	if k > 1 {
+		ym = at(k - 1)
+	}
+	c1 := (y1 - ym) / 2
+	c2 := ym - 2.5*y0 + 2*y1 - y2/2
+	c3 := (y2-ym)/2 + 1.5*(y0-y1)
+	return ((c3*t+c2)*t+c1)*t + y0
+}
@@ -0,0 +1,75 @@
+package effects_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/effects"
+)
+
+func TestDelayModes(t *testing.T) {
+	// the echoes of an impulse in both channels at multiples of the delay time
+	for _, tt := range []struct {
+		mode   effects.DelayMode
+		echoes [][2]float64
+	}{
+		{effects.DelayMono, [][2]float64{{1, 1}, {0.5, 0.5}, {0.25, 0.25}}},
+		{effects.DelayStereo, [][2]float64{{1, 0}, {0.5, 0}, {0.25, 0}}},
+		{effects.DelayPingPong, [][2]float64{{1, 0}, {0, 0.5}, {0.25, 0}}},
+	} {
+		impulse := &dataStreamer{data: [][2]float64{{1, 1}}}
+		if tt.mode == effects.DelayStereo {
+			impulse.data[0][1] = 0
+		}
+		delay := effects.NewDelay(1000, time.Second, impulse)
+		delay.Mode = tt.mode
+		delay.Time = 100
+		delay.TimeRight = 150
+		delay.Feedback = 0.5
+		delay.Wet, delay.Dry = 1, 0
+
+		got := collect(delay)
+		for i, echo := range tt.echoes {
+			if x := got[(i+1)*100]; math.Abs(x[0]-echo[0]) > 1e-9 || math.Abs(x[1]-echo[1]) > 1e-9 {
+				t.Errorf("mode %d: echo %d is %v, expected %v", tt.mode, i+1, x, echo)
+			}
+		}
+	}
+}
+
+func TestDelayTail(t *testing.T) {
+	// the delay is much longer than the input and the buffers it's streamed in
+	sr := beep.SampleRate(44100)
+	delay := effects.NewDelay(sr, time.Second, sineStreamer(1000, 0.01))
+	delay.Time = float64(sr.N(300 * time.Millisecond))
+	delay.ModRate, delay.ModDepth = 2, 20
+	delay.Dry = 0
+	got := collect(delay)
+	if len(got) <= sr.N(600*time.Millisecond) || len(got) > sr.N(10*time.Second) {
+		t.Fatalf("Delay streamed %d samples", len(got))
+	}
+
+	// the first two echoes
+	for _, at := range []int{sr.N(300 * time.Millisecond), sr.N(600 * time.Millisecond)} {
+		var energy float64
+		for _, x := range got[at-100 : at+1100] {
+			energy += x[0]*x[0] + x[1]*x[1]
+		}
+		if energy < 10 {
+			t.Errorf("echo at %d has energy %v, expected the delayed input", at, energy)
+		}
+	}
+	for _, x := range got[len(got)-10:] {
+		if math.Abs(x[0]) > 1e-3 || math.Abs(x[1]) > 1e-3 {
+			t.Fatalf("Delay tail didn't fade out: %v", x)
+		}
+	}
+}
+
+func TestNoteDelay(t *testing.T) {
+	if d := effects.NoteDelay(44100, 120, 1.0/4); d != 22050 {
+		t.Errorf("quarter note at 120 BPM is %v samples, expected 22050", d)
+	}
+}
@@ -0,0 +1,84 @@
+package effects
+
+import (
+	"math"
+	"time"
+
+	"github.com/faiface/beep"
+)
+
+// flangerMaxDelay is the longest delay of a Flanger.
+const flangerMaxDelay = 20 * time.Millisecond
+
+// NewFlanger returns a Flanger which sweeps a comb filter through the spectrum of s by mixing it
+// with a copy of itself delayed by a few milliseconds. The sample rate sr must match the sample
+// rate of s. The Flanger starts sweeping the delay between 0 and 5 ms with a 0.25 Hz triangle LFO
+// and a feedback of 0.5.
+//
+//   flanger := effects.NewFlanger(format.SampleRate, streamer)
+//   flanger.Feedback = -0.7
+//   speaker.Play(flanger)
+//
+// The Flanger keeps streaming the feedback after s is drained, until it fades out.
+//
+// The returned Flanger propagates s's errors.
+func NewFlanger(sr beep.SampleRate, s beep.Streamer) *Flanger {
+	size := sr.N(flangerMaxDelay) + 4
+	delay := float64(sr.N(2500 * time.Microsecond))
+	return &Flanger{
+		LFO:      LFO{Shape: LFOTriangle, Rate: 0.25, Depth: delay},
+		Delay:    delay,
+		Feedback: 0.5,
+		Wet:      0.5,
+		Dry:      0.5,
+		sr:       sr,
+		tail:     tail{s: s},
+		lines:    [2]delayLine{newDelayLine(size), newDelayLine(size)},
+	}
+}
+
+// Flanger is a flanger effect created by NewFlanger.
+//
+// If you're playing the Flanger through the speaker, you need to lock and unlock the speaker when
+// changing its parameters.
+type Flanger struct {
+	// LFO sweeps the delay, its Depth is in samples.
+	LFO LFO
+
+	// Delay is the delay in samples around which the LFO sweeps it, up to 20 ms. The delay doesn't
+	// go below one sample.
+	Delay float64
+
+	// Feedback feeds the delayed copy back into the delay, which makes the sweep more resonant. It
+	// is from -1 to 1, exclusive, negative values give a hollower sound.
+	Feedback float64
+
+	// Wet and Dry are the gains of the delayed copy and of the original audio.
+	Wet, Dry float64
+
+	sr    beep.SampleRate
+	tail  tail
+	lines [2]delayLine
+}
+
+// Stream streams the wrapped Streamer through the flanger.
+func (f *Flanger) Stream(samples [][2]float64) (n int, ok bool) {
+	return f.tail.stream(samples, int(f.Delay+math.Abs(f.LFO.Depth))+3, f.process)
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (f *Flanger) Err() error {
+	return f.tail.err()
+}
+
+func (f *Flanger) process(samples [][2]float64) {
+	for i := range samples {
+		v := f.LFO.next(f.sr)
+		for c := range v {
+			x := samples[i][c]
+			y := f.lines[c].read(f.Delay + v[c])
+			f.lines[c].write(x + y*f.Feedback)
+			samples[i][c] = x*f.Dry + y*f.Wet
+		}
+	}
+}
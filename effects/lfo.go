@@ -0,0 +1,85 @@
+package effects
+
+import (
+	"math"
+
+	"github.com/faiface/beep"
+)
+
+// LFOShape is the waveform of an LFO.
+type LFOShape int
+
+// Shapes of LFO. LFORandom glides smoothly to a new random value every cycle.
+const (
+	LFOSine LFOShape = iota
+	LFOTriangle
+	LFOSquare
+	LFORandom
+)
+
+// LFO is a low frequency oscillator, which drives the modulation effects. The meaning of Depth
+// depends on the effect. All the fields can be changed while streaming.
+type LFO struct {
+	Shape LFOShape
+
+	// Rate is the frequency of the LFO in Hz.
+	Rate float64
+
+	// Depth is the amount of the modulation, the LFO swings between -Depth and Depth.
+	Depth float64
+
+	// Phase is the phase offset of the right channel in cycles, for example 0.25 makes the right
+	// channel lag behind the left one by a quarter of a cycle, which widens the stereo image.
+	Phase float64
+
+	cycle int64   // number of the current cycle
+	frac  float64 // position within the current cycle
+}
+
+// next returns the values of the LFO for the left and the right channel and advances it by one
+// sample at the sample rate sr.
+func (l *LFO) next(sr beep.SampleRate) [2]float64 {
+	v := [2]float64{l.at(0), l.at(-l.Phase)}
+	l.frac += l.Rate / float64(sr)
+	if l.frac >= 1 || l.frac < 0 {
+		n := math.Floor(l.frac)
+		l.cycle += int64(n)
+		l.frac -= n
+	}
+	return v
+}
+
+// at returns the value of the LFO offset cycles from its current position.
+func (l *LFO) at(offset float64) float64 {
+	t := l.frac + offset
+	n := math.Floor(t)
+	cycle, f := l.cycle+int64(n), t-n
+
+	var v float64
+	switch l.Shape {
+	case LFOSine:
+		v = math.Sin(2 * math.Pi * f)
+	case LFOTriangle:
+		v = 4*math.Abs(f+0.75-math.Floor(f+0.75)-0.5) - 1
+	case LFOSquare:
+		v = 1
+		if f >= 0.5 {
+			v = -1
+		}
+	case LFORandom:
+		a, b := lfoRandom(cycle), lfoRandom(cycle+1)
+		v = a + (b-a)*(1-math.Cos(math.Pi*f))/2
+	}
+	return v * l.Depth
+}
+
+// lfoRandom returns a pseudo-random value between -1 and 1 for the cycle. It's a hash of the
+// cycle, so that both channels see the same random values regardless of their phase offset.
+func lfoRandom(cycle int64) float64 {
+	// splitmix64
+	z := uint64(cycle) + 0x9e3779b97f4a7c15
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+	z ^= z >> 31
+	return float64(z>>11)/(1<<52) - 1
+}
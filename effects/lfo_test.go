@@ -0,0 +1,164 @@
+package effects_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/effects"
+)
+
+// constStreamer returns a Streamer of numSamples samples of the value x.
+func constStreamer(numSamples int, x float64) beep.Streamer {
+	return beep.Take(numSamples, beep.StreamerFunc(func(samples [][2]float64) (n int, ok bool) {
+		for i := range samples {
+			samples[i] = [2]float64{x, x}
+		}
+		return len(samples), true
+	}))
+}
+
+// lfoValues returns the values of the LFO of the left and the right channel, recovered from the
+// gain of a Tremolo with depth 1.
+func lfoValues(numSamples int, lfo effects.LFO) [][2]float64 {
+	tremolo := effects.NewTremolo(1000, constStreamer(numSamples, 1))
+	tremolo.LFO = lfo
+	tremolo.LFO.Depth = 1
+	values := collect(tremolo)
+	for i := range values {
+		for c := range values[i] {
+			values[i][c] = 2*values[i][c] - 1
+		}
+	}
+	return values
+}
+
+func TestLFOShapes(t *testing.T) {
+	const period = 100 // samples at the rate of 10 Hz
+	shapes := map[effects.LFOShape]func(x float64) float64{
+		effects.LFOSine: func(x float64) float64 {
+			return math.Sin(2 * math.Pi * x)
+		},
+		effects.LFOTriangle: func(x float64) float64 {
+			x -= math.Floor(x)
+			switch {
+			case x < 0.25:
+				return 4 * x
+			case x < 0.75:
+				return 2 - 4*x
+			}
+			return 4*x - 4
+		},
+		effects.LFOSquare: func(x float64) float64 {
+			if x-math.Floor(x) < 0.5 {
+				return 1
+			}
+			return -1
+		},
+	}
+	for shape, want := range shapes {
+		values := lfoValues(3*period, effects.LFO{Shape: shape, Rate: 10, Phase: 0.25})
+		for i, v := range values {
+			x := float64(i) / period
+			if math.Abs(v[0]-want(x)) > 1e-9 || math.Abs(v[1]-want(x-0.25)) > 1e-9 {
+				t.Fatalf("shape %d: got %v at %v cycles, expected %v", shape, v, x, [2]float64{want(x), want(x - 0.25)})
+			}
+		}
+	}
+}
+
+func TestLFORandom(t *testing.T) {
+	const period = 100
+	// a phase of a whole cycle delays the right channel by one period
+	values := lfoValues(10*period, effects.LFO{Shape: effects.LFORandom, Rate: 10, Phase: 1})
+	for i, v := range values {
+		if v[0] < -1 || v[0] > 1 {
+			t.Fatalf("random value %v out of range", v[0])
+		}
+		if i > 0 && math.Abs(v[0]-values[i-1][0]) > math.Pi/period {
+			t.Fatalf("random values %v and %v not smooth", values[i-1][0], v[0])
+		}
+		if i >= period && math.Abs(v[1]-values[i-period][0]) > 1e-9 {
+			t.Fatalf("right channel %v, expected %v", v[1], values[i-period][0])
+		}
+	}
+}
+
+func TestModulationTail(t *testing.T) {
+	const numSamples = 5000
+	sr := beep.SampleRate(44100)
+	for _, tt := range []struct {
+		name   string
+		effect func(s beep.Streamer) beep.Streamer
+		tail   bool // whether the effect keeps streaming after s is drained
+	}{
+		{"Tremolo", func(s beep.Streamer) beep.Streamer { return effects.NewTremolo(sr, s) }, false},
+		{"Phaser", func(s beep.Streamer) beep.Streamer { return effects.NewPhaser(sr, s) }, false},
+		{"Chorus", func(s beep.Streamer) beep.Streamer { return effects.NewChorus(sr, s) }, true},
+		{"Flanger", func(s beep.Streamer) beep.Streamer { return effects.NewFlanger(sr, s) }, true},
+		{"Vibrato", func(s beep.Streamer) beep.Streamer { return effects.NewVibrato(sr, s) }, true},
+	} {
+		got := collect(tt.effect(sineStreamer(numSamples, 0.01)))
+		if !tt.tail {
+			if len(got) != numSamples {
+				t.Errorf("%s streamed %d samples from %d", tt.name, len(got), numSamples)
+			}
+			continue
+		}
+		if len(got) <= numSamples || len(got) > numSamples+sr.N(time.Second) {
+			t.Errorf("%s streamed %d samples from %d", tt.name, len(got), numSamples)
+			continue
+		}
+		for _, x := range got[len(got)-10:] {
+			if math.Abs(x[0]) > 1e-3 || math.Abs(x[1]) > 1e-3 {
+				t.Errorf("%s tail didn't fade out: %v", tt.name, x)
+				break
+			}
+		}
+	}
+}
+
+func TestModulationDelayedVoice(t *testing.T) {
+	// the input ends long before the delayed voice comes out
+	sr := beep.SampleRate(44100)
+	for _, tt := range []struct {
+		name   string
+		effect func(s beep.Streamer) beep.Streamer
+	}{
+		{"Chorus", func(s beep.Streamer) beep.Streamer {
+			c := effects.NewChorus(sr, s)
+			c.Delay, c.Dry = float64(sr.N(50*time.Millisecond)), 0
+			return c
+		}},
+		{"Flanger", func(s beep.Streamer) beep.Streamer {
+			f := effects.NewFlanger(sr, s)
+			f.Delay, f.Dry = float64(sr.N(15*time.Millisecond)), 0
+			return f
+		}},
+		{"Vibrato", func(s beep.Streamer) beep.Streamer {
+			v := effects.NewVibrato(sr, s)
+			v.LFO.Depth = float64(sr.N(15 * time.Millisecond))
+			return v
+		}},
+	} {
+		var energy float64
+		for _, x := range collect(tt.effect(sineStreamer(100, 0.01))) {
+			energy += x[0]*x[0] + x[1]*x[1]
+		}
+		if energy < 10 {
+			t.Errorf("%s streamed energy %v, expected the delayed input", tt.name, energy)
+		}
+	}
+}
+
+func TestVibrato(t *testing.T) {
+	// the delay is modulated, but a constant stays constant after the initial delay
+	vibrato := effects.NewVibrato(44100, constStreamer(10000, 0.5))
+	got := collect(vibrato)
+	for i, x := range got[2000:10000] {
+		if math.Abs(x[0]-0.5) > 1e-9 || math.Abs(x[1]-0.5) > 1e-9 {
+			t.Fatalf("Vibrato sample %d is %v, expected 0.5", 2000+i, x)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package effects
+
+import (
+	"math"
+
+	"github.com/faiface/beep"
+)
+
+// phaserMaxStages is the largest number of allpass stages of a Phaser.
+const phaserMaxStages = 12
+
+// NewPhaser returns a Phaser which sweeps notches through the spectrum of s. The sample rate sr
+// must match the sample rate of s. The Phaser starts with 4 stages sweeping between 200 Hz and
+// 2000 Hz with a 0.5 Hz sine LFO, with the channels a quarter of a cycle apart.
+//
+//   phaser := effects.NewPhaser(format.SampleRate, streamer)
+//   phaser.Stages = 8
+//   phaser.Feedback = 0.6
+//   speaker.Play(phaser)
+//
+// The returned Phaser propagates s's errors.
+func NewPhaser(sr beep.SampleRate, s beep.Streamer) *Phaser {
+	return &Phaser{
+		Streamer: s,
+		LFO:      LFO{Rate: 0.5, Depth: 1, Phase: 0.25},
+		Stages:   4,
+		MinFreq:  200,
+		MaxFreq:  2000,
+		Wet:      0.5,
+		Dry:      0.5,
+		sr:       sr,
+	}
+}
+
+// Phaser is a phaser effect created by NewPhaser. It feeds each channel through a chain of
+// first-order allpass filters and mixes the result with the original audio, which cancels out the
+// frequencies where the chain shifts the phase by a half cycle.
+//
+// If you're playing the Phaser through the speaker, you need to lock and unlock the speaker when
+// changing its parameters.
+type Phaser struct {
+	Streamer beep.Streamer
+
+	// LFO sweeps the break frequency of the allpass filters. Its Depth is from 0 (no sweep, the
+	// break frequency stays in the middle) to 1 (the sweep covers the whole range from MinFreq to
+	// MaxFreq).
+	LFO LFO
+
+	// Stages is the number of allpass filters, up to 12. Each two stages add one notch.
+	Stages int
+
+	// MinFreq and MaxFreq are the range of the sweep in Hz.
+	MinFreq, MaxFreq float64
+
+	// Feedback feeds the output of the allpass chain back into its input, which makes the notches
+	// sharper. It is from -1 to 1, exclusive.
+	Feedback float64
+
+	// Wet and Dry are the gains of the allpass chain and of the original audio. Equal gains make
+	// the deepest notches.
+	Wet, Dry float64
+
+	sr     beep.SampleRate
+	stages [2][phaserMaxStages]struct{ x, y float64 } // last input and output of each stage
+	last   [2]float64                                 // last output of the chain
+}
+
+// Stream streams the wrapped Streamer through the phaser.
+func (p *Phaser) Stream(samples [][2]float64) (n int, ok bool) {
+	n, ok = p.Streamer.Stream(samples)
+	stages := p.Stages
+	if stages < 0 {
+		stages = 0
+	}
+	if stages > phaserMaxStages {
+		stages = phaserMaxStages
+	}
+	lo, hi := p.MinFreq, p.MaxFreq
+	if lo < 1 {
+		lo = 1
+	}
+	if nyquist := float64(p.sr) / 2 * 0.99; hi > nyquist {
+		hi = nyquist
+	}
+	if hi < lo {
+		hi = lo
+	}
+
+	for i := range samples[:n] {
+		v := p.LFO.next(p.sr)
+		for c := range v {
+			// exponential sweep, so that the notches move evenly in pitch
+			sweep := math.Max(0, math.Min(1, (1+v[c])/2))
+			freq := lo * math.Pow(hi/lo, sweep)
+			t := math.Tan(math.Pi * freq / float64(p.sr))
+			a := (t - 1) / (t + 1)
+
+			x := samples[i][c]
+			y := x + p.last[c]*p.Feedback
+			for j := range p.stages[c][:stages] {
+				st := &p.stages[c][j]
+				out := a*y + st.x - a*st.y
+				st.x, st.y = y, out
+				y = out
+			}
+			p.last[c] = y
+			samples[i][c] = x*p.Dry + y*p.Wet
+		}
+	}
+	return n, ok
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (p *Phaser) Err() error {
+	return p.Streamer.Err()
+}
@@ -0,0 +1,298 @@
+package effects
+
+import (
+	"math"
+	"math/cmplx"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/fft"
+)
+
+// PitchShift changes the pitch of the wrapped Streamer without changing its tempo or duration.
+// Semitones is the shift in semitones, the fractional part gives cents (0.25 is 25 cents up, -12 is
+// an octave down). Semitones can be changed while streaming.
+//
+// When Formants is true, the spectral envelope of the audio is preserved, so that shifted voices
+// keep their character instead of sounding like chipmunks or giants. The level stays the same as
+// without preserving the formants.
+//
+//   shift := &effects.PitchShift{Streamer: s, Semitones: 3}
+//   speaker.Play(shift)
+//   // ...
+//   speaker.Lock()
+//   shift.Semitones = -2
+//   speaker.Unlock()
+//
+// PitchShift uses a phase vocoder with frames of 2048 samples. It reads the wrapped Streamer that
+// far ahead, which keeps the output aligned with the input.
+type PitchShift struct {
+	Streamer  beep.Streamer
+	Semitones float64
+	Formants  bool
+
+	state *pitchShiftState
+}
+
+const (
+	pitchShiftSize    = 2048 // frame size
+	pitchShiftOverlap = 4    // number of frames overlapping at each sample
+	pitchShiftHop     = pitchShiftSize / pitchShiftOverlap
+	pitchShiftLifter  = 40 // number of cepstral coefficients of the spectral envelope
+)
+
+type pitchShiftState struct {
+	plan   *fft.Plan
+	window []float64
+
+	in    [][2]float64 // window of the input
+	inOff int          // position of in[0] in the input
+	end   int          // length of the input, or -1 if it's not drained yet
+	next  int          // position of the next frame in the input
+
+	lastPhase [2][]float64 // phases of the last analyzed frame
+	sumPhase  [2][]float64 // phases of the last synthesized frame
+	acc       [2][]float64 // overlap-add accumulators
+
+	frame             []float64
+	spectrum          []complex128
+	magn, phase       []float64
+	synMagn, synPhase []float64
+	synPlain          []float64 // shifted magnitudes without preserving the formants
+	envelope          []float64
+	cepstrum          []complex128
+
+	out [][2]float64 // finished samples waiting to be streamed
+	tmp [512][2]float64
+}
+
+func newPitchShiftState() *pitchShiftState {
+	const bins = pitchShiftSize/2 + 1
+	st := &pitchShiftState{
+		plan:     fft.NewPlan(pitchShiftSize),
+		window:   fft.Hann(pitchShiftSize),
+		end:      -1,
+		next:     -(pitchShiftSize - pitchShiftHop),
+		frame:    make([]float64, pitchShiftSize),
+		spectrum: make([]complex128, bins),
+		magn:     make([]float64, bins),
+		phase:    make([]float64, bins),
+		synMagn:  make([]float64, bins),
+		synPhase: make([]float64, bins),
+		synPlain: make([]float64, bins),
+		envelope: make([]float64, bins),
+		cepstrum: make([]complex128, bins),
+	}
+	// the frames before the start of the input see silence
+	st.in = make([][2]float64, -st.next)
+	st.inOff = st.next
+	for c := range st.acc {
+		st.lastPhase[c] = make([]float64, bins)
+		st.sumPhase[c] = make([]float64, bins)
+		st.acc[c] = make([]float64, pitchShiftSize)
+	}
+	return st
+}
+
+// Stream streams the wrapped Streamer with its pitch shifted.
+func (p *PitchShift) Stream(samples [][2]float64) (n int, ok bool) {
+	if p.state == nil {
+		p.state = newPitchShiftState()
+	}
+	st := p.state
+	for n < len(samples) {
+		if len(st.out) == 0 {
+			if !p.process() {
+				break
+			}
+			continue
+		}
+		k := copy(samples[n:], st.out)
+		st.out = st.out[k:]
+		n += k
+	}
+	return n, n > 0
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (p *PitchShift) Err() error {
+	return p.Streamer.Err()
+}
+
+// process analyzes the next frame, shifts it and finishes the output samples at the start of it.
+// It returns false when the input is exhausted.
+func (p *PitchShift) process() bool {
+	st := p.state
+	start := st.next
+	st.fill(p.Streamer, start+pitchShiftSize)
+	if st.end >= 0 && start >= st.end {
+		return false
+	}
+
+	ratio := math.Pow(2, p.Semitones/12)
+	for c := range st.acc {
+		for i := range st.frame {
+			st.frame[i] = st.at(start + i)[c] * st.window[i]
+		}
+		st.plan.Real(st.spectrum, st.frame)
+		st.shift(c, ratio, p.Formants)
+		st.plan.RealInverse(st.frame, st.spectrum)
+
+		// Hann windows applied twice with 75% overlap sum to 1.5
+		acc := st.acc[c]
+		for i := range acc {
+			acc[i] += st.frame[i] * st.window[i] / 1.5
+		}
+	}
+
+	// output the samples which no later frame overlaps, except for the ones before the start or
+	// after the end of the input
+	st.out = st.out[:0]
+	for i := 0; i < pitchShiftHop; i++ {
+		if pos := start + i; pos >= 0 && (st.end < 0 || pos < st.end) {
+			st.out = append(st.out, [2]float64{st.acc[0][i], st.acc[1][i]})
+		}
+	}
+	for c := range st.acc {
+		acc := st.acc[c]
+		copy(acc, acc[pitchShiftHop:])
+		for i := range acc[pitchShiftSize-pitchShiftHop:] {
+			acc[pitchShiftSize-pitchShiftHop+i] = 0
+		}
+	}
+
+	st.next += pitchShiftHop
+	st.drop()
+	return true
+}
+
+// shift shifts the spectrum of the channel c by the ratio. The true frequency of each peak is
+// estimated from the phase difference to the previous frame. The bins around each peak are moved
+// together to the shifted peak, keeping their phases relative to the peak (identity phase
+// locking), and the phase of the shifted peak is advanced by its shifted frequency, which keeps
+// the shifted partials continuous.
+func (st *pitchShiftState) shift(c int, ratio float64, formants bool) {
+	// the expected phase advance of bin 1 between frames
+	expected := 2 * math.Pi * pitchShiftHop / pitchShiftSize
+
+	for k, x := range st.spectrum {
+		st.magn[k], st.phase[k] = cmplx.Abs(x), cmplx.Phase(x)
+	}
+	if formants {
+		st.spectralEnvelope()
+	}
+
+	for j := range st.synMagn {
+		st.synMagn[j] = 0
+		st.synPlain[j] = 0
+	}
+	bins := len(st.magn)
+	// each peak owns the bins from the minimum after the previous peak to the minimum before the
+	// next one
+	for lo := 0; lo < bins; {
+		peak := lo
+		for peak+1 < bins && st.magn[peak+1] >= st.magn[peak] {
+			peak++
+		}
+		hi := peak + 1
+		for hi < bins && st.magn[hi] < st.magn[hi-1] {
+			hi++
+		}
+
+		dp := st.phase[peak] - st.lastPhase[c][peak] - float64(peak)*expected
+		dp -= 2 * math.Pi * math.Round(dp/(2*math.Pi))
+		freq := (float64(peak) + dp/expected) * ratio
+
+		j := int(math.Round(float64(peak) * ratio))
+		if j < bins {
+			phase := st.sumPhase[c][j] + freq*expected
+			for i := lo; i < hi; i++ {
+				if ji := j + i - peak; 0 <= ji && ji < bins {
+					if formants {
+						// the magnitudes are moved without the envelope, which is applied
+						// at their new place
+						st.synMagn[ji] += st.magn[i] / st.envelope[i]
+						st.synPlain[ji] += st.magn[i]
+					} else {
+						st.synMagn[ji] += st.magn[i]
+					}
+					st.synPhase[ji] = phase + st.phase[i] - st.phase[peak]
+				}
+			}
+		}
+		lo = hi
+	}
+	copy(st.lastPhase[c], st.phase)
+
+	// the envelope only shapes the spectrum, the energy of the frame stays the same as without it,
+	// otherwise partials moved away from the peaks of the envelope would get much quieter
+	gain := 1.0
+	if formants {
+		var plain, shaped float64
+		for j, m := range st.synMagn {
+			m *= st.envelope[j]
+			plain += st.synPlain[j] * st.synPlain[j]
+			shaped += m * m
+		}
+		if shaped > 0 {
+			gain = math.Sqrt(plain / shaped)
+		}
+	}
+
+	for j := range st.spectrum {
+		magn := st.synMagn[j]
+		if formants {
+			magn *= st.envelope[j] * gain
+		}
+		if magn > 0 {
+			st.sumPhase[c][j] = st.synPhase[j]
+		}
+		st.spectrum[j] = cmplx.Rect(magn, st.synPhase[j])
+	}
+}
+
+// spectralEnvelope calculates the spectral envelope of the magnitudes by smoothing them in the
+// cepstral domain.
+func (st *pitchShiftState) spectralEnvelope() {
+	for k, m := range st.magn {
+		st.cepstrum[k] = complex(math.Log(m+1e-9), 0)
+	}
+	st.plan.RealInverse(st.frame, st.cepstrum)
+	for i := pitchShiftLifter; i <= pitchShiftSize-pitchShiftLifter; i++ {
+		st.frame[i] = 0
+	}
+	st.plan.Real(st.cepstrum, st.frame)
+	for k := range st.envelope {
+		st.envelope[k] = math.Exp(real(st.cepstrum[k]))
+	}
+}
+
+// at returns the input sample at position i, or silence if it's out of the input.
+func (st *pitchShiftState) at(i int) [2]float64 {
+	if i < st.inOff || i >= st.inOff+len(st.in) {
+		return [2]float64{}
+	}
+	return st.in[i-st.inOff]
+}
+
+// fill reads the input until it contains the position hi, or the input is drained.
+func (st *pitchShiftState) fill(s beep.Streamer, hi int) {
+	for st.end < 0 && st.inOff+len(st.in) < hi {
+		sn, sok := s.Stream(st.tmp[:])
+		st.in = append(st.in, st.tmp[:sn]...)
+		if !sok {
+			st.end = st.inOff + len(st.in)
+		}
+	}
+}
+
+// drop removes the input samples before the next frame.
+func (st *pitchShiftState) drop() {
+	if k := st.next - st.inOff; k > pitchShiftSize {
+		// at the end of the input, the next frame can start past the buffered samples
+		if k > len(st.in) {
+			k = len(st.in)
+		}
+		st.in = st.in[:copy(st.in, st.in[k:])]
+		st.inOff += k
+	}
+}
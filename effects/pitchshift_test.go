@@ -0,0 +1,129 @@
+package effects_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/analysis"
+	"github.com/faiface/beep/effects"
+)
+
+// sineStreamer returns a Streamer of numSamples samples of a sine wave with the frequency freq in
+// cycles per sample.
+func sineStreamer(numSamples int, freq float64) beep.Streamer {
+	i := 0
+	return beep.Take(numSamples, beep.StreamerFunc(func(samples [][2]float64) (n int, ok bool) {
+		for j := range samples {
+			x := math.Sin(2 * math.Pi * freq * float64(i))
+			samples[j] = [2]float64{x, x}
+			i++
+		}
+		return len(samples), true
+	}))
+}
+
+// collect drains Streamer s and returns all of the samples it streamed.
+func collect(s beep.Streamer) [][2]float64 {
+	var (
+		result [][2]float64
+		buf    [479][2]float64
+	)
+	for {
+		n, ok := s.Stream(buf[:])
+		if !ok {
+			return result
+		}
+		result = append(result, buf[:n]...)
+	}
+}
+
+// peakFrequency returns the frequency in Hz of the strongest partial in the first numSamples
+// samples of s, which is sampled at the sample rate sr. The frequency is interpolated between the
+// bins of the spectrum.
+func peakFrequency(sr beep.SampleRate, numSamples int, s beep.Streamer) float64 {
+	const size = 8192
+	spectrum := analysis.NewSpectrum(sr, size, size, beep.Take(numSamples, s))
+	collect(spectrum)
+	frame := spectrum.Frame()
+	peak := 1
+	for i := 1; i < len(frame)-1; i++ {
+		if frame[i] > frame[peak] {
+			peak = i
+		}
+	}
+	a, b, c := math.Log(frame[peak-1]), math.Log(frame[peak]), math.Log(frame[peak+1])
+	offset := (a - c) / (2 * (a - 2*b + c))
+	return (float64(peak) + offset) * float64(sr) / size
+}
+
+// rms returns the root mean square level of the samples.
+func rms(samples [][2]float64) float64 {
+	var sum float64
+	for _, x := range samples {
+		sum += x[0]*x[0] + x[1]*x[1]
+	}
+	return math.Sqrt(sum / float64(2*len(samples)))
+}
+
+func TestPitchShiftLength(t *testing.T) {
+	lengths := []int{0, 1, 511, 512, 1000, 2048, 2536, 44100}
+	for i := 0; i < 30; i++ {
+		lengths = append(lengths, rand.Intn(20000))
+	}
+	for _, formants := range []bool{false, true} {
+		for _, numSamples := range lengths {
+			shift := &effects.PitchShift{
+				Streamer:  sineStreamer(numSamples, 0.01),
+				Semitones: 5,
+				Formants:  formants,
+			}
+			got := collect(shift)
+			if len(got) != numSamples {
+				t.Errorf("formants %v: got %d samples from %d", formants, len(got), numSamples)
+			}
+		}
+	}
+}
+
+func TestPitchShiftFrequency(t *testing.T) {
+	const (
+		sr   = beep.SampleRate(44100)
+		freq = 440.0
+	)
+	for _, formants := range []bool{false, true} {
+		for _, semitones := range []float64{-12, 7, 12} {
+			shift := &effects.PitchShift{
+				Streamer:  sineStreamer(sr.N(time.Second), freq/float64(sr)),
+				Semitones: semitones,
+				Formants:  formants,
+			}
+			got := peakFrequency(sr, 4*8192, shift)
+			want := freq * math.Pow(2, semitones/12)
+			if math.Abs(got-want) > want*0.01 {
+				t.Errorf("formants %v, %v semitones: got %.1f Hz, want %.1f Hz", formants, semitones, got, want)
+			}
+		}
+	}
+}
+
+func TestPitchShiftLevel(t *testing.T) {
+	const sr = beep.SampleRate(44100)
+	want := rms(collect(sineStreamer(sr.N(time.Second), 440/float64(sr))))
+	for _, formants := range []bool{false, true} {
+		for _, semitones := range []float64{-12, -5, 7, 12} {
+			shift := &effects.PitchShift{
+				Streamer:  sineStreamer(sr.N(time.Second), 440/float64(sr)),
+				Semitones: semitones,
+				Formants:  formants,
+			}
+			// skip the fade in and out of the shifted audio
+			got := rms(collect(shift)[4096 : sr.N(time.Second)-4096])
+			if got < want*0.8 || got > want*1.2 {
+				t.Errorf("formants %v, %v semitones: got level %.3f, want %.3f", formants, semitones, got, want)
+			}
+		}
+	}
+}
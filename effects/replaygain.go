@@ -0,0 +1,162 @@
+package effects
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/analysis"
+)
+
+// ReplayGainReference is the loudness in LUFS which ReplayGain 2.0 normalizes to.
+const ReplayGainReference = -18.0
+
+// ReplayGainMode selects which of the gains Apply uses.
+type ReplayGainMode int
+
+// Modes for Apply. Track mode makes all tracks equally loud, album mode keeps the loudness
+// differences between the tracks of an album.
+const (
+	ReplayGainTrack ReplayGainMode = iota
+	ReplayGainAlbum
+)
+
+// ReplayGain holds the normalization gains of a track in decibels and its peaks as linear
+// amplitudes. A zero peak means the peak is unknown.
+type ReplayGain struct {
+	TrackGain, TrackPeak float64
+	AlbumGain, AlbumPeak float64
+}
+
+// ParseReplayGain reads ReplayGain from the tags of a file, as returned by a tag reading library.
+// The tag names are case insensitive, the recognized ones are REPLAYGAIN_TRACK_GAIN,
+// REPLAYGAIN_TRACK_PEAK, REPLAYGAIN_ALBUM_GAIN and REPLAYGAIN_ALBUM_PEAK. Gains are in the usual
+// "-6.50 dB" format.
+//
+// If only the track or only the album values are present, they are used for both. If none are
+// present, or a value can't be parsed, ParseReplayGain returns an error.
+func ParseReplayGain(tags map[string]string) (ReplayGain, error) {
+	var (
+		rg                         ReplayGain
+		hasTrack, hasAlbum         bool
+		hasTrackPeak, hasAlbumPeak bool
+	)
+	for key, value := range tags {
+		var (
+			dst  *float64
+			gain bool
+		)
+		switch strings.ToUpper(key) {
+		case "REPLAYGAIN_TRACK_GAIN":
+			dst, gain, hasTrack = &rg.TrackGain, true, true
+		case "REPLAYGAIN_TRACK_PEAK":
+			dst, hasTrackPeak = &rg.TrackPeak, true
+		case "REPLAYGAIN_ALBUM_GAIN":
+			dst, gain, hasAlbum = &rg.AlbumGain, true, true
+		case "REPLAYGAIN_ALBUM_PEAK":
+			dst, hasAlbumPeak = &rg.AlbumPeak, true
+		default:
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if gain {
+			value = strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(value, "dB"), "db"))
+		}
+		v, err := strconv.ParseFloat(strings.TrimPrefix(value, "+"), 64)
+		if err != nil {
+			return ReplayGain{}, fmt.Errorf("replay gain: invalid %s: %q", key, tags[key])
+		}
+		*dst = v
+	}
+
+	switch {
+	case !hasTrack && !hasAlbum:
+		return ReplayGain{}, fmt.Errorf("replay gain: no gain tags")
+	case !hasAlbum:
+		rg.AlbumGain = rg.TrackGain
+	case !hasTrack:
+		rg.TrackGain = rg.AlbumGain
+	}
+	if !hasAlbumPeak {
+		rg.AlbumPeak = rg.TrackPeak
+	}
+	if !hasTrackPeak {
+		rg.TrackPeak = rg.AlbumPeak
+	}
+	return rg, nil
+}
+
+// ReplayGainFromLoudness calculates ReplayGain from loudness measured by the analysis package, for
+// example precomputed by analysis.ScanLoudness and stored in a database. Silent audio gets no gain.
+func ReplayGainFromLoudness(track, album analysis.LoudnessStats) ReplayGain {
+	gain := func(loudness float64) float64 {
+		if math.IsInf(loudness, -1) {
+			return 0
+		}
+		return ReplayGainReference - loudness
+	}
+	return ReplayGain{
+		TrackGain: gain(track.Integrated),
+		TrackPeak: track.TruePeak,
+		AlbumGain: gain(album.Integrated),
+		AlbumPeak: album.TruePeak,
+	}
+}
+
+// ScanReplayGain measures the loudness of the tracks of an album and calculates their ReplayGain.
+// All the tracks are streamed once, one after another, and seeked back to their beginning
+// afterwards. The sample rate sr must match the sample rate of the tracks.
+//
+// ScanReplayGain returns the first error of the tracks, if any.
+func ScanReplayGain(sr beep.SampleRate, tracks ...beep.StreamSeeker) ([]ReplayGain, error) {
+	loudness := make([]*analysis.Loudness, len(tracks))
+	streamers := make([]beep.Streamer, len(tracks))
+	for i, track := range tracks {
+		if err := track.Seek(0); err != nil {
+			return nil, err
+		}
+		loudness[i] = analysis.NewLoudness(sr, track)
+		streamers[i] = loudness[i]
+	}
+
+	// the album is measured as a whole, so that its loudness is the loudness of all the audio, not
+	// the average of the tracks
+	album, err := analysis.ScanLoudness(sr, beep.Seq(streamers...))
+	if err != nil {
+		return nil, err
+	}
+
+	gains := make([]ReplayGain, len(tracks))
+	for i, track := range tracks {
+		if err := track.Err(); err != nil {
+			return nil, err
+		}
+		if err := track.Seek(0); err != nil {
+			return nil, err
+		}
+		gains[i] = ReplayGainFromLoudness(loudness[i].Stats(), album)
+	}
+	return gains, nil
+}
+
+// Apply returns a Gain which normalizes s according to the ReplayGain and the mode. The preamp in
+// decibels is added to the gain, for example to make the normalized audio louder than the
+// reference.
+//
+// If the peak is known, the gain is lowered so that the peak doesn't exceed full scale.
+func (rg ReplayGain) Apply(mode ReplayGainMode, preamp float64, s beep.Streamer) *Gain {
+	db, peak := rg.TrackGain, rg.TrackPeak
+	if mode == ReplayGainAlbum {
+		db, peak = rg.AlbumGain, rg.AlbumPeak
+	}
+	gain := math.Pow(10, (db+preamp)/20)
+	if peak > 0 && gain*peak > 1 {
+		gain = 1 / peak
+	}
+	return &Gain{
+		Streamer: s,
+		Gain:     gain - 1,
+	}
+}
@@ -0,0 +1,161 @@
+package effects_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/faiface/beep/effects"
+)
+
+func TestParseReplayGain(t *testing.T) {
+	for _, tt := range []struct {
+		tags map[string]string
+		want effects.ReplayGain
+	}{
+		{
+			map[string]string{
+				"replaygain_track_gain": "+2.50 dB",
+				"REPLAYGAIN_TRACK_PEAK": "0.9",
+				"ReplayGain_Album_Gain": "-6.5 dB",
+				"REPLAYGAIN_ALBUM_PEAK": " 1.1 ",
+				"TITLE":                 "Song",
+			},
+			effects.ReplayGain{TrackGain: 2.5, TrackPeak: 0.9, AlbumGain: -6.5, AlbumPeak: 1.1},
+		},
+		{
+			// only the track values, used for the album too
+			map[string]string{"REPLAYGAIN_TRACK_GAIN": "-3 db", "REPLAYGAIN_TRACK_PEAK": "0.5"},
+			effects.ReplayGain{TrackGain: -3, TrackPeak: 0.5, AlbumGain: -3, AlbumPeak: 0.5},
+		},
+		{
+			// only the album values, used for the track too
+			map[string]string{"REPLAYGAIN_ALBUM_GAIN": "1.25"},
+			effects.ReplayGain{TrackGain: 1.25, AlbumGain: 1.25},
+		},
+	} {
+		got, err := effects.ParseReplayGain(tt.tags)
+		if err != nil {
+			t.Errorf("ParseReplayGain(%v) failed: %v", tt.tags, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseReplayGain(%v) = %+v, expected %+v", tt.tags, got, tt.want)
+		}
+	}
+
+	for _, tags := range []map[string]string{
+		{},
+		{"TITLE": "Song"},
+		{"REPLAYGAIN_TRACK_PEAK": "0.9"},
+		{"REPLAYGAIN_TRACK_GAIN": "loud"},
+		{"REPLAYGAIN_TRACK_GAIN": "-3 dB", "REPLAYGAIN_TRACK_PEAK": "0.9.1"},
+		{"REPLAYGAIN_ALBUM_GAIN": "dB"},
+	} {
+		if rg, err := effects.ParseReplayGain(tags); err == nil {
+			t.Errorf("ParseReplayGain(%v) = %+v, expected an error", tags, rg)
+		}
+	}
+}
+
+func TestReplayGainApply(t *testing.T) {
+	rg := effects.ReplayGain{TrackGain: -6, TrackPeak: 0.5, AlbumGain: 10, AlbumPeak: 0.8}
+	for _, tt := range []struct {
+		mode   effects.ReplayGainMode
+		preamp float64
+		want   float64
+	}{
+		{effects.ReplayGainTrack, 0, math.Pow(10, -6.0/20)},
+		{effects.ReplayGainTrack, 6, 1},
+		// the gain is lowered, so that the peak doesn't exceed full scale
+		{effects.ReplayGainTrack, 20, 2},
+		{effects.ReplayGainAlbum, 0, 1.25},
+		{effects.ReplayGainAlbum, -20, math.Pow(10, -10.0/20)},
+	} {
+		got := collect(rg.Apply(tt.mode, tt.preamp, constStreamer(10, 0.5)))
+		if len(got) != 10 || math.Abs(got[0][0]-0.5*tt.want) > 1e-9 {
+			t.Errorf("mode %d, preamp %v: got %v, expected gain %v", tt.mode, tt.preamp, got[0], tt.want)
+		}
+	}
+
+	// unknown peaks don't limit the gain
+	rg = effects.ReplayGain{TrackGain: 20}
+	if got := collect(rg.Apply(effects.ReplayGainTrack, 0, constStreamer(1, 0.5))); math.Abs(got[0][0]-5) > 1e-9 {
+		t.Errorf("got %v with an unknown peak, expected 5", got[0][0])
+	}
+}
+
+// trackStreamer is a StreamSeeker of the data, which fails with err at its end, if it's not nil.
+type trackStreamer struct {
+	data [][2]float64
+	pos  int
+	err  error
+}
+
+func newTrack(numSamples int, amp float64) *trackStreamer {
+	data := collect(sineStreamer(numSamples, 1000.0/48000))
+	for i := range data {
+		data[i][0] *= amp
+		data[i][1] *= amp
+	}
+	return &trackStreamer{data: data}
+}
+
+func (ts *trackStreamer) Stream(samples [][2]float64) (n int, ok bool) {
+	if ts.pos >= len(ts.data) {
+		return 0, false
+	}
+	n = copy(samples, ts.data[ts.pos:])
+	ts.pos += n
+	return n, true
+}
+
+func (ts *trackStreamer) Err() error {
+	if ts.pos >= len(ts.data) {
+		return ts.err
+	}
+	return nil
+}
+
+func (ts *trackStreamer) Len() int      { return len(ts.data) }
+func (ts *trackStreamer) Position() int { return ts.pos }
+
+func (ts *trackStreamer) Seek(p int) error {
+	ts.pos = p
+	return nil
+}
+
+func TestScanReplayGain(t *testing.T) {
+	// 1 kHz sines at -20 and -30 dBFS measure -20 and -30 LUFS
+	quiet, loud := newTrack(5*48000, math.Pow(10, -30.0/20)), newTrack(5*48000, math.Pow(10, -20.0/20))
+	loud.Seek(1000)
+	gains, err := effects.ScanReplayGain(48000, loud, quiet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gains) != 2 {
+		t.Fatalf("got %d gains, expected 2", len(gains))
+	}
+	if math.Abs(gains[0].TrackGain-2) > 0.1 || math.Abs(gains[1].TrackGain-12) > 0.1 {
+		t.Errorf("track gains %v and %v dB, expected 2 and 12", gains[0].TrackGain, gains[1].TrackGain)
+	}
+	// the album is as loud as its tracks together
+	album := effects.ReplayGainReference + 20 - 10*math.Log10((1+0.1)/2)
+	for _, rg := range gains {
+		if math.Abs(rg.AlbumGain-album) > 0.1 || rg.AlbumPeak != gains[0].TrackPeak {
+			t.Errorf("album gain %v dB and peak %v, expected %v dB and %v", rg.AlbumGain, rg.AlbumPeak, album, gains[0].TrackPeak)
+		}
+	}
+	if math.Abs(gains[0].TrackPeak-0.1) > 0.005 {
+		t.Errorf("track peak %v, expected 0.1", gains[0].TrackPeak)
+	}
+	if loud.Position() != 0 || quiet.Position() != 0 {
+		t.Errorf("tracks at %d and %d after scanning, expected 0", loud.Position(), quiet.Position())
+	}
+
+	broken := newTrack(48000, 0.1)
+	broken.err = errors.New("broken")
+	if _, err := effects.ScanReplayGain(48000, loud, broken, quiet); err == nil {
+		t.Error("ScanReplayGain didn't propagate the error of a track")
+	}
+}
@@ -0,0 +1,185 @@
+package effects
+
+import (
+	"time"
+
+	"github.com/faiface/beep"
+)
+
+// The tunings of the Freeverb algorithm by Jezar at Dreampoint, in samples at 44100 Hz.
+var (
+	reverbCombs     = [...]int{1116, 1188, 1277, 1356, 1422, 1491, 1557, 1617}
+	reverbAllpasses = [...]int{556, 441, 341, 225}
+)
+
+const (
+	reverbSpread     = 23 // difference of the right channel tunings
+	reverbInputGain  = 0.015
+	reverbMaxDelay   = time.Second
+	reverbRoomScale  = 0.28
+	reverbRoomOffset = 0.7
+	reverbDampScale  = 0.4
+)
+
+// NewReverb returns a Reverb which adds algorithmic reverberation to s. The sample rate sr must
+// match the sample rate of s. The parameters start at a medium sized room with the reverb mixed
+// under the original audio and can be changed while streaming.
+//
+//   reverb := effects.NewReverb(format.SampleRate, streamer)
+//   reverb.RoomSize = 0.8
+//   speaker.Play(reverb)
+//
+// The Reverb keeps streaming the reverb tail after s is drained, until it fades out.
+//
+// The returned Reverb propagates s's errors.
+func NewReverb(sr beep.SampleRate, s beep.Streamer) *Reverb {
+	r := &Reverb{
+		RoomSize: 0.5,
+		Damping:  0.5,
+		Wet:      0.3,
+		Dry:      1,
+		Width:    1,
+		sr:       sr,
+		tail:     tail{s: s},
+		pre:      make([]float64, sr.N(reverbMaxDelay)+1),
+	}
+	scale := func(n int) int {
+		n = n * int(sr) / 44100
+		if n < 1 {
+			n = 1
+		}
+		return n
+	}
+	for c := range r.combs {
+		for i, n := range reverbCombs {
+			r.combs[c][i].buf = make([]float64, scale(n+c*reverbSpread))
+		}
+		for i, n := range reverbAllpasses {
+			r.allpasses[c][i].buf = make([]float64, scale(n+c*reverbSpread))
+		}
+	}
+	return r
+}
+
+// Reverb is an algorithmic reverb created by NewReverb. It's based on Freeverb, which feeds the
+// mono mix of the input through eight parallel lowpass feedback comb filters and four series
+// allpass filters for each channel.
+//
+// If you're playing the Reverb through the speaker, you need to lock and unlock the speaker when
+// changing its parameters.
+type Reverb struct {
+	// RoomSize sets the length of the reverb, from 0 (a small room) to 1 (a large hall).
+	RoomSize float64
+
+	// Damping sets how quickly the high frequencies fade out, from 0 (bright) to 1 (dark).
+	Damping float64
+
+	// Wet and Dry are the gains of the reverb and of the original audio.
+	Wet, Dry float64
+
+	// Width sets the stereo width of the reverb, from 0 (mono) to 1 (full stereo).
+	Width float64
+
+	// PreDelay delays the reverb after the original audio, up to one second.
+	PreDelay time.Duration
+
+	sr        beep.SampleRate
+	tail      tail
+	combs     [2][len(reverbCombs)]reverbComb
+	allpasses [2][len(reverbAllpasses)]reverbAllpass
+	pre       []float64 // pre-delay line of the mono mix of the input
+	preI      int
+}
+
+// Stream streams the wrapped Streamer with the reverb.
+func (r *Reverb) Stream(samples [][2]float64) (n int, ok bool) {
+	// the longest way through the reverb is the pre-delay, the longest comb and all the allpasses
+	delay := r.preDelay()
+	longest := 0
+	for c := range r.combs {
+		n := 0
+		for _, f := range r.combs[c] {
+			if len(f.buf) > n {
+				n = len(f.buf)
+			}
+		}
+		for _, f := range r.allpasses[c] {
+			n += len(f.buf)
+		}
+		if n > longest {
+			longest = n
+		}
+	}
+	return r.tail.stream(samples, delay+longest, r.process)
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (r *Reverb) Err() error {
+	return r.tail.err()
+}
+
+func (r *Reverb) process(samples [][2]float64) {
+	feedback := r.RoomSize*reverbRoomScale + reverbRoomOffset
+	damp := r.Damping * reverbDampScale
+	wet1 := r.Wet * (r.Width/2 + 0.5)
+	wet2 := r.Wet * (1 - r.Width) / 2
+	delay := r.preDelay()
+
+	for i, x := range samples {
+		r.pre[r.preI] = (x[0] + x[1]) * reverbInputGain
+		in := r.pre[(r.preI-delay+len(r.pre))%len(r.pre)]
+		r.preI = (r.preI + 1) % len(r.pre)
+
+		var out [2]float64
+		for c := range out {
+			for j := range r.combs[c] {
+				out[c] += r.combs[c][j].process(in, feedback, damp)
+			}
+			for j := range r.allpasses[c] {
+				out[c] = r.allpasses[c][j].process(out[c])
+			}
+		}
+		samples[i][0] = x[0]*r.Dry + out[0]*wet1 + out[1]*wet2
+		samples[i][1] = x[1]*r.Dry + out[1]*wet1 + out[0]*wet2
+	}
+}
+
+// preDelay returns the pre-delay in samples, limited to the length of the pre-delay line.
+func (r *Reverb) preDelay() int {
+	delay := r.sr.N(r.PreDelay)
+	if delay < 0 {
+		delay = 0
+	}
+	if delay >= len(r.pre) {
+		delay = len(r.pre) - 1
+	}
+	return delay
+}
+
+// reverbComb is a comb filter with a one-pole lowpass in the feedback path.
+type reverbComb struct {
+	buf   []float64
+	i     int
+	store float64 // state of the lowpass
+}
+
+func (f *reverbComb) process(x, feedback, damp float64) float64 {
+	y := f.buf[f.i]
+	f.store = y*(1-damp) + f.store*damp
+	f.buf[f.i] = x + f.store*feedback
+	f.i = (f.i + 1) % len(f.buf)
+	return y
+}
+
+// reverbAllpass is the Schroeder allpass filter of Freeverb.
+type reverbAllpass struct {
+	buf []float64
+	i   int
+}
+
+func (f *reverbAllpass) process(x float64) float64 {
+	b := f.buf[f.i]
+	f.buf[f.i] = x + b*0.5
+	f.i = (f.i + 1) % len(f.buf)
+	return b - x
+}
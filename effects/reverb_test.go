@@ -0,0 +1,56 @@
+package effects_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/effects"
+)
+
+func TestReverbTail(t *testing.T) {
+	sr := beep.SampleRate(44100)
+	for _, roomSize := range []float64{0, 0.5, 1} {
+		for _, preDelay := range []time.Duration{0, 20 * time.Millisecond, 200 * time.Millisecond} {
+			reverb := effects.NewReverb(sr, sineStreamer(1000, 0.01))
+			reverb.RoomSize = roomSize
+			reverb.PreDelay = preDelay
+			reverb.Dry = 0
+			got := collect(reverb)
+			if len(got) <= 1000+sr.N(preDelay) || len(got) > sr.N(30*time.Second) {
+				t.Fatalf("room size %v, pre-delay %v: Reverb streamed %d samples", roomSize, preDelay, len(got))
+			}
+
+			// the reverb of the input comes after the input ends
+			var energy float64
+			for _, x := range got[1000:] {
+				energy += x[0]*x[0] + x[1]*x[1]
+			}
+			if energy < 1 {
+				t.Errorf("room size %v, pre-delay %v: Reverb tail energy %v, expected the reverb of the input", roomSize, preDelay, energy)
+			}
+			for _, x := range got[len(got)-10:] {
+				if math.Abs(x[0]) > 1e-3 || math.Abs(x[1]) > 1e-3 {
+					t.Fatalf("room size %v, pre-delay %v: Reverb tail didn't fade out: %v", roomSize, preDelay, x)
+				}
+			}
+		}
+	}
+}
+
+func TestReverbDry(t *testing.T) {
+	want := collect(sineStreamer(1000, 0.01))
+	reverb := effects.NewReverb(44100, sineStreamer(1000, 0.01))
+	reverb.Wet = 0
+	got := collect(reverb)
+	if len(got) < len(want) || !reflect.DeepEqual(want, got[:len(want)]) {
+		t.Fatal("Reverb without the wet signal not equal to the original")
+	}
+	for _, x := range got[len(want):] {
+		if x != [2]float64{} {
+			t.Fatalf("Reverb without the wet signal streamed %v after the original", x)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package effects
+
+import "github.com/faiface/beep"
+
+// tailSilence is the level below which the tail of an effect is considered silent.
+const tailSilence = 1e-5
+
+// tail streams a Streamer through an effect which keeps sounding after the Streamer is drained,
+// such as a reverb or a delay. Once the Streamer is drained, tail keeps feeding silence into the
+// effect until its output stays silent for longer than the longest delay inside the effect, so
+// that nothing is still on its way through the effect.
+type tail struct {
+	s       beep.Streamer
+	drained bool
+	done    bool
+	silent  int // number of consecutive silent samples of the output since the Streamer drained
+}
+
+// stream streams the Streamer into samples and processes them in place with process. The delay is
+// the longest delay in samples inside the effect.
+func (t *tail) stream(samples [][2]float64, delay int, process func(samples [][2]float64)) (n int, ok bool) {
+	if t.done {
+		return 0, false
+	}
+	if !t.drained {
+		n, ok = t.s.Stream(samples)
+		if !ok && t.s.Err() != nil {
+			t.done = true
+			return 0, false
+		}
+		if !ok || n < len(samples) {
+			t.drained = true
+		}
+	}
+	for i := range samples[n:] {
+		samples[n+i] = [2]float64{}
+	}
+	process(samples)
+
+	if t.drained {
+		for _, x := range samples[n:] {
+			if x[0] > tailSilence || x[0] < -tailSilence || x[1] > tailSilence || x[1] < -tailSilence {
+				t.silent = 0
+				continue
+			}
+			t.silent++
+		}
+		if t.silent > delay {
+			t.done = true
+		}
+	}
+	return len(samples), true
+}
+
+// err propagates the Streamer's errors.
+func (t *tail) err() error {
+	return t.s.Err()
+}
@@ -0,0 +1,260 @@
+package effects
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/faiface/beep"
+)
+
+// TimeStretch returns a TimeStretcher which plays s at the rate (1 is the original tempo, 2 is
+// twice as fast, 0.5 is half the speed) without changing its pitch. The sample rate sr must match
+// the sample rate of s.
+//
+// Unlike changing the resampling ratio of a beep.Resampler, which changes the tempo and the pitch
+// together, TimeStretch uses WSOLA (waveform similarity overlap-add). It cuts the audio into short
+// overlapping frames and places them closer together or further apart, choosing each frame within
+// a small tolerance so that it continues the waveform of the previous one as smoothly as possible.
+// It works best for speech and music without sharp transients.
+//
+//   stretch := effects.TimeStretch(format.SampleRate, 0.75, streamer)
+//   speaker.Play(stretch)
+//   // ...
+//   speaker.Lock()
+//   stretch.SetRate(0.5)
+//   speaker.Unlock()
+//
+// At a constant rate, the output is as long as s divided by the rate.
+//
+// TimeStretch panics if the rate is not positive.
+//
+// The returned TimeStretcher propagates s's errors.
+func TimeStretch(sr beep.SampleRate, rate float64, s beep.Streamer) *TimeStretcher {
+	size := sr.N(40*time.Millisecond) / 2 * 2
+	if size < 4 {
+		size = 4
+	}
+	hop := size / 2
+	tolerance := sr.N(10 * time.Millisecond)
+
+	// the frames are periodic Hann windows, which sum to one with 50% overlap
+	window := make([]float64, size)
+	for i := range window {
+		window[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(size))
+	}
+
+	// the first frame starts hop samples before the start of s, so that the output starts with
+	// two overlapping frames, the first hop samples of the output are skipped
+	start := -size - tolerance
+	ts := &TimeStretcher{
+		s:         s,
+		size:      size,
+		hop:       hop,
+		tolerance: tolerance,
+		window:    window,
+		in:        make([][2]float64, -start),
+		inOff:     start,
+		end:       -1,
+		pos:       float64(-hop),
+		prev:      -2 * hop,
+		acc:       make([][2]float64, size),
+		skip:      hop,
+	}
+	ts.SetRate(rate)
+	return ts
+}
+
+// TimeStretcher is a Streamer created by TimeStretch. The rate can be changed while streaming, it
+// changes with the next frame.
+type TimeStretcher struct {
+	s         beep.Streamer
+	rate      float64
+	size      int // frame size
+	hop       int // distance of the frames in the output
+	tolerance int // how far a frame can be from its nominal position in the input
+	window    []float64
+
+	in    [][2]float64 // window of the input
+	inOff int          // position of in[0] in the input
+	end   int          // length of the input, or -1 if it's not drained yet
+
+	pos   float64 // nominal position of the next frame in the input
+	prev  int     // position of the previous frame in the input
+	outIn float64 // position in the input corresponding to the end of the output so far
+
+	acc  [][2]float64 // overlap-add accumulator
+	out  [][2]float64 // finished samples waiting to be streamed
+	skip int          // number of samples to skip at the start
+	tmp  [512][2]float64
+}
+
+// Rate returns the current tempo rate.
+func (ts *TimeStretcher) Rate() float64 {
+	return ts.rate
+}
+
+// SetRate sets the tempo rate. SetRate panics if the rate is not positive.
+func (ts *TimeStretcher) SetRate(rate float64) {
+	if rate <= 0 {
+		panic(fmt.Errorf("time stretch: invalid rate: %v", rate))
+	}
+	// the position of the next frame was advanced at the old rate, once the output started
+	if ts.skip == 0 {
+		ts.pos += float64(ts.hop) * (rate - ts.rate)
+	}
+	ts.rate = rate
+}
+
+// Stream streams the time stretched audio.
+func (ts *TimeStretcher) Stream(samples [][2]float64) (n int, ok bool) {
+	for n < len(samples) {
+		if len(ts.out) == 0 {
+			if !ts.next() {
+				break
+			}
+			continue
+		}
+		k := copy(samples[n:], ts.out)
+		ts.out = ts.out[k:]
+		n += k
+	}
+	return n, n > 0
+}
+
+// Err propagates the original Streamer's errors.
+func (ts *TimeStretcher) Err() error {
+	return ts.s.Err()
+}
+
+// next places the next frame and finishes hop samples of the output. It returns false when the
+// output reaches the end of the input.
+func (ts *TimeStretcher) next() bool {
+	nominal := int(math.Round(ts.pos))
+	ts.fill(nominal + ts.tolerance + ts.size)
+	if ts.end >= 0 && ts.outIn >= float64(ts.end) {
+		return false
+	}
+
+	// near the end, the frames past the end would match the silence after the input, so they're
+	// kept within the input
+	last := nominal + ts.tolerance
+	if ts.end >= 0 && last > ts.end-ts.size {
+		last = ts.end - ts.size
+	}
+	if nominal > last && last >= ts.inOff {
+		nominal = last
+	}
+
+	// find the frame most similar to the natural continuation of the previous frame, first
+	// coarsely, then around the best coarse match
+	target := ts.prev + ts.hop
+	best, bestScore := nominal, ts.similarity(target, nominal)
+	search := func(from, to, step int) {
+		if from < ts.inOff {
+			from = ts.inOff
+		}
+		if to > last {
+			to = last
+		}
+		for k := from; k <= to; k += step {
+			if score := ts.similarity(target, k); score > bestScore {
+				best, bestScore = k, score
+			}
+		}
+	}
+	search(nominal-ts.tolerance, nominal+ts.tolerance, 4)
+	search(best-3, best+3, 1)
+
+	for i := range ts.acc {
+		x, w := ts.at(best+i), ts.window[i]
+		ts.acc[i][0] += x[0] * w
+		ts.acc[i][1] += x[1] * w
+	}
+	ts.out = append(ts.out[:0], ts.acc[:ts.hop]...)
+	copy(ts.acc, ts.acc[ts.hop:])
+	for i := range ts.acc[ts.size-ts.hop:] {
+		ts.acc[ts.size-ts.hop+i] = [2]float64{}
+	}
+	if ts.skip > 0 {
+		k := ts.skip
+		if k > len(ts.out) {
+			k = len(ts.out)
+		}
+		ts.out = ts.out[k:]
+		ts.skip -= k
+	}
+
+	// the finished output ends at the middle of the frame, which is derived from the nominal
+	// position rather than summed up from the rates, so that it stays in sync with the frames when
+	// the rate changes
+	outIn := ts.pos + float64(ts.hop)
+	// the frames past the end of the input flush the accumulator, the output ends where it
+	// reaches the end of the input
+	if ts.end >= 0 && outIn > float64(ts.end) && len(ts.out) > 0 {
+		rate := (outIn - ts.outIn) / float64(len(ts.out))
+		if left := int(math.Ceil((float64(ts.end) - ts.outIn) / rate)); len(ts.out) > left {
+			ts.out = ts.out[:left]
+		}
+		outIn = float64(ts.end)
+	}
+	ts.outIn = outIn
+
+	ts.prev = best
+	ts.pos += float64(ts.hop) * ts.rate
+	ts.drop()
+	return true
+}
+
+// similarity returns the normalized cross-correlation of the mono mixes of the input starting at
+// a and at b. Every other sample is skipped to save time.
+func (ts *TimeStretcher) similarity(a, b int) float64 {
+	var xy, yy float64
+	for i := 0; i < ts.hop; i += 2 {
+		x, y := ts.at(a+i), ts.at(b+i)
+		xm, ym := x[0]+x[1], y[0]+y[1]
+		xy += xm * ym
+		yy += ym * ym
+	}
+	return xy / math.Sqrt(yy+1e-9)
+}
+
+// at returns the input sample at position i, or silence if it's out of the input.
+func (ts *TimeStretcher) at(i int) [2]float64 {
+	if i < ts.inOff || i >= ts.inOff+len(ts.in) {
+		return [2]float64{}
+	}
+	return ts.in[i-ts.inOff]
+}
+
+// fill reads the input until it contains the position hi, or the input is drained.
+func (ts *TimeStretcher) fill(hi int) {
+	for ts.end < 0 && ts.inOff+len(ts.in) <= hi {
+		sn, sok := ts.s.Stream(ts.tmp[:])
+		ts.in = append(ts.in, ts.tmp[:sn]...)
+		if !sok {
+			ts.end = ts.inOff + len(ts.in)
+		}
+	}
+}
+
+// drop removes the input samples which won't be needed anymore.
+func (ts *TimeStretcher) drop() {
+	lo := ts.prev + ts.hop
+	next := int(ts.pos)
+	if ts.end >= 0 && next > ts.end-ts.size {
+		// near the end, the frames are kept within the input
+		next = ts.end - ts.size
+	}
+	if next-ts.tolerance-4 < lo {
+		lo = next - ts.tolerance - 4
+	}
+	if k := lo - ts.inOff; k > 4*len(ts.tmp) {
+		// at the end of the input, the frames can be past the buffered samples
+		if k > len(ts.in) {
+			k = len(ts.in)
+		}
+		ts.in = ts.in[:copy(ts.in, ts.in[k:])]
+		ts.inOff += k
+	}
+}
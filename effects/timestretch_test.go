@@ -0,0 +1,98 @@
+package effects_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/effects"
+)
+
+func TestTimeStretchLength(t *testing.T) {
+	for i := 0; i < 30; i++ {
+		numSamples := rand.Intn(60000)
+		rate := 0.25 + rand.Float64()*3
+		if i < 4 {
+			numSamples, rate = 44100, []float64{0.5, 1, 2, 2.5}[i]
+		}
+		stretch := effects.TimeStretch(44100, rate, sineStreamer(numSamples, 0.01))
+		got := collect(stretch)
+		want := math.Ceil(float64(numSamples) / rate)
+		if math.Abs(float64(len(got))-want) > 1 {
+			t.Errorf("rate %v: got %d samples from %d, want %v", rate, len(got), numSamples, want)
+		}
+	}
+}
+
+func TestTimeStretchEnd(t *testing.T) {
+	// the last overlapping frames are flushed, the output doesn't end with silence
+	for _, rate := range []float64{0.5, 0.75, 1, 2.5} {
+		got := collect(effects.TimeStretch(44100, rate, sineStreamer(44100, 0.01)))
+		var peak float64
+		for _, x := range got[len(got)-200:] {
+			peak = math.Max(peak, math.Abs(x[0]))
+		}
+		if peak < 0.5 {
+			t.Errorf("rate %v: got peak %v at the end, want 1", rate, peak)
+		}
+	}
+}
+
+func TestTimeStretchPitch(t *testing.T) {
+	const (
+		sr   = beep.SampleRate(44100)
+		freq = 440.0
+	)
+	for _, rate := range []float64{0.5, 1, 2} {
+		stretch := effects.TimeStretch(sr, rate, sineStreamer(2*sr.N(time.Second), freq/float64(sr)))
+		got := peakFrequency(sr, 4*8192, stretch)
+		if math.Abs(got-freq) > freq*0.01 {
+			t.Errorf("rate %v: got %.1f Hz, want %.1f Hz", rate, got, freq)
+		}
+	}
+}
+
+func TestTimeStretchSetRate(t *testing.T) {
+	const (
+		numSamples = 88200
+		freq       = 0.01
+	)
+	for _, rates := range [][2]float64{{1, 2}, {2, 0.5}, {0.5, 3}, {1.5, 0.75}} {
+		for _, at := range []int{1000, 12345, 30000} {
+			stretch := effects.TimeStretch(44100, rates[0], sineStreamer(numSamples, freq))
+			got := make([][2]float64, at)
+			if n, _ := stretch.Stream(got); n != at {
+				t.Fatalf("rates %v: streamed %d samples, want %d", rates, n, at)
+			}
+			stretch.SetRate(rates[1])
+			got = append(got, collect(stretch)...)
+
+			// the output already finished at the old rate is up to one hop of 20 ms longer
+			want := float64(at) + (float64(numSamples)-float64(at)*rates[0])/rates[1]
+			if math.Abs(float64(len(got))-want) > 882*math.Abs(1-rates[0]/rates[1])+2 {
+				t.Errorf("rates %v at %d: got %d samples, want about %.0f", rates, at, len(got), want)
+			}
+
+			// the sine keeps going without clicks or drops in level
+			maxStep := 2 * math.Pi * freq * 1.5
+			for i := 1; i < len(got); i++ {
+				if step := math.Abs(got[i][0] - got[i-1][0]); step > maxStep {
+					t.Errorf("rates %v at %d: got a click of %.3f at %d", rates, at, step, i)
+					break
+				}
+			}
+			for i := 200; i+200 < len(got); i += 100 {
+				var peak float64
+				for _, x := range got[i-100 : i+100] {
+					peak = math.Max(peak, math.Abs(x[0]))
+				}
+				if peak < 0.7 {
+					t.Errorf("rates %v at %d: got level %.3f at %d, want 1", rates, at, peak, i)
+					break
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package effects
+
+import "github.com/faiface/beep"
+
+// NewTremolo returns a Tremolo which periodically changes the volume of s. The sample rate sr must
+// match the sample rate of s. The LFO starts at a 5 Hz sine with a depth of 0.5.
+//
+//   tremolo := effects.NewTremolo(format.SampleRate, streamer)
+//   tremolo.LFO.Shape = effects.LFOSquare
+//   tremolo.LFO.Phase = 0.5 // auto-pan
+//   speaker.Play(tremolo)
+//
+// The returned Tremolo propagates s's errors.
+func NewTremolo(sr beep.SampleRate, s beep.Streamer) *Tremolo {
+	return &Tremolo{
+		Streamer: s,
+		LFO:      LFO{Rate: 5, Depth: 0.5},
+		sr:       sr,
+	}
+}
+
+// Tremolo is a tremolo effect created by NewTremolo. The Depth of the LFO is from 0 (no effect) to
+// 1 (the volume drops to silence at the bottom of each cycle).
+//
+// If you're playing the Tremolo through the speaker, you need to lock and unlock the speaker when
+// changing the LFO.
+type Tremolo struct {
+	Streamer beep.Streamer
+	LFO      LFO
+
+	sr beep.SampleRate
+}
+
+// Stream streams the wrapped Streamer with the volume modulated.
+func (t *Tremolo) Stream(samples [][2]float64) (n int, ok bool) {
+	n, ok = t.Streamer.Stream(samples)
+	for i := range samples[:n] {
+		v := t.LFO.next(t.sr)
+		samples[i][0] *= 1 - t.LFO.Depth/2 + v[0]/2
+		samples[i][1] *= 1 - t.LFO.Depth/2 + v[1]/2
+	}
+	return n, ok
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (t *Tremolo) Err() error {
+	return t.Streamer.Err()
+}
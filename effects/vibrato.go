@@ -0,0 +1,69 @@
+package effects
+
+import (
+	"math"
+	"time"
+
+	"github.com/faiface/beep"
+)
+
+// vibratoMaxDelay is the largest Depth of a Vibrato.
+const vibratoMaxDelay = 20 * time.Millisecond
+
+// NewVibrato returns a Vibrato which periodically bends the pitch of s up and down. The sample
+// rate sr must match the sample rate of s. The LFO starts at a 5 Hz sine with a depth of 1 ms,
+// which bends the pitch by about half a semitone.
+//
+//   vibrato := effects.NewVibrato(format.SampleRate, streamer)
+//   vibrato.LFO.Rate = 7
+//   speaker.Play(vibrato)
+//
+// The returned Vibrato propagates s's errors.
+func NewVibrato(sr beep.SampleRate, s beep.Streamer) *Vibrato {
+	size := 2*sr.N(vibratoMaxDelay) + 4
+	return &Vibrato{
+		LFO:   LFO{Rate: 5, Depth: float64(sr.N(time.Millisecond))},
+		sr:    sr,
+		tail:  tail{s: s},
+		lines: [2]delayLine{newDelayLine(size), newDelayLine(size)},
+	}
+}
+
+// Vibrato is a vibrato effect created by NewVibrato. It only plays a copy of the audio delayed by
+// a varying time, the faster the delay changes, the more the pitch bends. The bend in semitones is
+// roughly 12 * log2(1 + 2π * Rate * Depth / sample rate).
+//
+// The Depth of the LFO is in samples, up to 20 ms. The audio is delayed by Depth samples on
+// average.
+//
+// If you're playing the Vibrato through the speaker, you need to lock and unlock the speaker when
+// changing the LFO.
+type Vibrato struct {
+	LFO LFO
+
+	sr    beep.SampleRate
+	tail  tail
+	lines [2]delayLine
+}
+
+// Stream streams the wrapped Streamer with the pitch modulated.
+func (v *Vibrato) Stream(samples [][2]float64) (n int, ok bool) {
+	return v.tail.stream(samples, int(1+2*math.Abs(v.LFO.Depth))+3, v.process)
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (v *Vibrato) Err() error {
+	return v.tail.err()
+}
+
+func (v *Vibrato) process(samples [][2]float64) {
+	for i := range samples {
+		delay := 1 + math.Abs(v.LFO.Depth)
+		mod := v.LFO.next(v.sr)
+		for c := range mod {
+			y := v.lines[c].read(delay + mod[c])
+			v.lines[c].write(samples[i][c])
+			samples[i][c] = y
+		}
+	}
+}
@@ -0,0 +1,3 @@
+// Package fft implements the fast Fourier transform, window functions and the short-time Fourier
+// transform for frequency-domain processing in the Beep library.
+package fft
@@ -0,0 +1,154 @@
+package fft
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+// Plan holds precomputed tables for transforms of one size. Creating a Plan is relatively costly,
+// so create it once and reuse it.
+//
+// A Plan uses internal buffers, so it must not be used from multiple goroutines at the same time.
+type Plan struct {
+	n       int
+	twiddle []complex128 // twiddle[k] = e^(-2πik/n), for k < n/2
+	rev     []int        // bit reversal permutation
+
+	half    *Plan        // plan of size n/2 for the real transforms
+	scratch []complex128 // buffer of size n/2 for the real transforms
+}
+
+// NewPlan creates a Plan for transforms of size n. NewPlan panics if n is not a power of two.
+func NewPlan(n int) *Plan {
+	if n < 1 || n&(n-1) != 0 {
+		panic(fmt.Errorf("fft: size is not a power of two: %d", n))
+	}
+	p := &Plan{
+		n:       n,
+		twiddle: make([]complex128, n/2),
+		rev:     make([]int, n),
+	}
+	for k := range p.twiddle {
+		p.twiddle[k] = cmplx.Rect(1, -2*math.Pi*float64(k)/float64(n))
+	}
+	bits := 0
+	for 1<<bits < n {
+		bits++
+	}
+	for i := range p.rev {
+		for b := 0; b < bits; b++ {
+			if i&(1<<b) != 0 {
+				p.rev[i] |= 1 << (bits - 1 - b)
+			}
+		}
+	}
+	return p
+}
+
+// Len returns the size of the transforms of the Plan.
+func (p *Plan) Len() int {
+	return p.n
+}
+
+// Forward calculates the discrete Fourier transform of x in place. The length of x must be the
+// size of the Plan.
+func (p *Plan) Forward(x []complex128) {
+	p.transform(x, false)
+}
+
+// Inverse calculates the inverse discrete Fourier transform of x in place, including the scaling
+// by 1/n, so that Inverse undoes Forward. The length of x must be the size of the Plan.
+func (p *Plan) Inverse(x []complex128) {
+	p.transform(x, true)
+	scale := complex(1/float64(p.n), 0)
+	for i := range x {
+		x[i] *= scale
+	}
+}
+
+// transform is an iterative radix-2 Cooley-Tukey FFT.
+func (p *Plan) transform(x []complex128, inverse bool) {
+	if len(x) != p.n {
+		panic(fmt.Errorf("fft: invalid length %d, expected %d", len(x), p.n))
+	}
+	for i, j := range p.rev {
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+	for size := 2; size <= p.n; size *= 2 {
+		half, step := size/2, p.n/size
+		for start := 0; start < p.n; start += size {
+			for k := 0; k < half; k++ {
+				w := p.twiddle[k*step]
+				if inverse {
+					w = cmplx.Conj(w)
+				}
+				a, b := x[start+k], w*x[start+k+half]
+				x[start+k], x[start+k+half] = a+b, a-b
+			}
+		}
+	}
+}
+
+// Real calculates the discrete Fourier transform of the real signal src into dst. Because the
+// transform of a real signal is symmetric, only the first n/2+1 values are calculated. The length
+// of src must be the size of the Plan, which must be at least 2, and the length of dst must be
+// n/2+1.
+func (p *Plan) Real(dst []complex128, src []float64) {
+	p.checkReal(dst, src)
+	n2 := p.n / 2
+	// transform the even samples as the real parts and the odd samples as the imaginary parts at
+	// once, then separate the two transforms
+	z := p.scratch
+	for k := range z {
+		z[k] = complex(src[2*k], src[2*k+1])
+	}
+	p.half.Forward(z)
+	for k := 0; k <= n2; k++ {
+		zk, zr := z[k%n2], cmplx.Conj(z[(n2-k)%n2])
+		even := (zk + zr) / 2
+		odd := (zk - zr) / 2i
+		dst[k] = even + p.w(k)*odd
+	}
+}
+
+// RealInverse calculates the inverse of Real, reconstructing the real signal dst from the first
+// n/2+1 values of its transform src.
+func (p *Plan) RealInverse(dst []float64, src []complex128) {
+	p.checkReal(src, dst)
+	n2 := p.n / 2
+	z := p.scratch
+	for k := range z {
+		xk, xr := src[k], cmplx.Conj(src[n2-k])
+		even := (xk + xr) / 2
+		odd := (xk - xr) / 2 * cmplx.Conj(p.w(k))
+		z[k] = even + 1i*odd
+	}
+	p.half.Inverse(z)
+	for k := range z {
+		dst[2*k], dst[2*k+1] = real(z[k]), imag(z[k])
+	}
+}
+
+// w returns e^(-2πik/n) for k <= n/2.
+func (p *Plan) w(k int) complex128 {
+	if k == p.n/2 {
+		return -1
+	}
+	return p.twiddle[k]
+}
+
+func (p *Plan) checkReal(spectrum []complex128, signal []float64) {
+	if p.n < 2 {
+		panic(fmt.Errorf("fft: real transform of size %d", p.n))
+	}
+	if len(signal) != p.n || len(spectrum) != p.n/2+1 {
+		panic(fmt.Errorf("fft: invalid lengths %d and %d, expected %d and %d", len(signal), len(spectrum), p.n, p.n/2+1))
+	}
+	if p.half == nil {
+		p.half = NewPlan(p.n / 2)
+		p.scratch = make([]complex128, p.n/2)
+	}
+}
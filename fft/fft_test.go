@@ -0,0 +1,132 @@
+package fft_test
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"testing"
+
+	"github.com/faiface/beep/fft"
+)
+
+// dft calculates the discrete Fourier transform of x directly.
+func dft(x []complex128) []complex128 {
+	n := len(x)
+	y := make([]complex128, n)
+	for k := range y {
+		for j, xj := range x {
+			y[k] += xj * cmplx.Rect(1, -2*math.Pi*float64(j*k)/float64(n))
+		}
+	}
+	return y
+}
+
+func randomSignal(n int) []float64 {
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = rand.Float64()*2 - 1
+	}
+	return x
+}
+
+func TestForward(t *testing.T) {
+	for _, n := range []int{1, 2, 4, 8, 64, 512} {
+		x := make([]complex128, n)
+		for i := range x {
+			x[i] = complex(rand.Float64()*2-1, rand.Float64()*2-1)
+		}
+		want := dft(x)
+
+		plan := fft.NewPlan(n)
+		got := append([]complex128(nil), x...)
+		plan.Forward(got)
+		for k := range got {
+			if cmplx.Abs(got[k]-want[k]) > 1e-9 {
+				t.Fatalf("size %d: Forward[%d] = %v, expected %v", n, k, got[k], want[k])
+			}
+		}
+
+		plan.Inverse(got)
+		for i := range got {
+			if cmplx.Abs(got[i]-x[i]) > 1e-12 {
+				t.Fatalf("size %d: Inverse[%d] = %v, expected %v", n, i, got[i], x[i])
+			}
+		}
+	}
+}
+
+func TestReal(t *testing.T) {
+	for _, n := range []int{2, 4, 8, 64, 512} {
+		x := randomSignal(n)
+		cx := make([]complex128, n)
+		for i := range x {
+			cx[i] = complex(x[i], 0)
+		}
+		want := dft(cx)
+
+		plan := fft.NewPlan(n)
+		got := make([]complex128, n/2+1)
+		plan.Real(got, x)
+		for k := range got {
+			if cmplx.Abs(got[k]-want[k]) > 1e-9 {
+				t.Fatalf("size %d: Real[%d] = %v, expected %v", n, k, got[k], want[k])
+			}
+		}
+
+		back := make([]float64, n)
+		plan.RealInverse(back, got)
+		for i := range back {
+			if math.Abs(back[i]-x[i]) > 1e-12 {
+				t.Fatalf("size %d: RealInverse[%d] = %v, expected %v", n, i, back[i], x[i])
+			}
+		}
+	}
+}
+
+func TestSTFT(t *testing.T) {
+	const size, hop = 64, 16
+	stft := fft.NewSTFT(size, hop, fft.Hann)
+	x := randomSignal(1000)
+	window := fft.Hann(size)
+	plan := fft.NewPlan(size)
+	want := make([]complex128, size/2+1)
+	frame := make([]float64, size)
+
+	frames := 0
+	// pushing in uneven chunks gives the same frames as transforming them directly
+	for i := 0; i < len(x); {
+		k := 1 + rand.Intn(50)
+		if k > len(x)-i {
+			k = len(x) - i
+		}
+		stft.Push(x[i:i+k], func(spectrum []complex128) {
+			for j := range frame {
+				frame[j] = x[frames*hop+j] * window[j]
+			}
+			plan.Real(want, frame)
+			for j := range spectrum {
+				if cmplx.Abs(spectrum[j]-want[j]) > 1e-9 {
+					t.Fatalf("frame %d: spectrum[%d] = %v, expected %v", frames, j, spectrum[j], want[j])
+				}
+			}
+			frames++
+		})
+		i += k
+	}
+	if want := (len(x)-size)/hop + 1; frames != want {
+		t.Fatalf("STFT produced %d frames, expected %d", frames, want)
+	}
+}
+
+func TestNewSTFTPanics(t *testing.T) {
+	for _, args := range [][2]int{{1, 1}, {0, 1}, {48, 16}, {64, 0}, {64, 65}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("NewSTFT(%d, %d) didn't panic", args[0], args[1])
+				}
+			}()
+			fft.NewSTFT(args[0], args[1], fft.Hann)
+		}()
+	}
+}
@@ -0,0 +1,78 @@
+package fft
+
+import "fmt"
+
+// NewSTFT creates an STFT which transforms frames of size samples, windowed by window, starting
+// every hop samples. For example, a hop of size/4 makes the frames overlap by 75%.
+//
+// NewSTFT panics if size is not a power of two of at least 2, or hop is not between 1 and size.
+func NewSTFT(size, hop int, window Window) *STFT {
+	if size < 2 || size&(size-1) != 0 {
+		panic(fmt.Errorf("fft: invalid STFT size: %d", size))
+	}
+	if hop < 1 || size < hop {
+		panic(fmt.Errorf("fft: invalid STFT hop %d for size %d", hop, size))
+	}
+	return &STFT{
+		plan:     NewPlan(size),
+		window:   window(size),
+		hop:      hop,
+		buf:      make([]float64, 0, size),
+		frame:    make([]float64, size),
+		spectrum: make([]complex128, size/2+1),
+	}
+}
+
+// STFT is the short-time Fourier transform. It splits a signal into overlapping frames, windows
+// them and transforms each of them with the real FFT.
+type STFT struct {
+	plan     *Plan
+	window   []float64
+	hop      int
+	buf      []float64 // samples of the next frame collected so far
+	frame    []float64
+	spectrum []complex128
+}
+
+// Len returns the size of the frames.
+func (s *STFT) Len() int {
+	return s.plan.Len()
+}
+
+// Hop returns the number of samples between the starts of the frames.
+func (s *STFT) Hop() int {
+	return s.hop
+}
+
+// Window returns the window applied to the frames.
+func (s *STFT) Window() []float64 {
+	return s.window
+}
+
+// Push adds the samples x to the signal and calls f with the spectrum of each frame completed by
+// them. The spectrum has Len()/2+1 values and is only valid until f returns.
+func (s *STFT) Push(x []float64, f func(spectrum []complex128)) {
+	size := s.plan.Len()
+	for len(x) > 0 {
+		k := size - len(s.buf)
+		if k > len(x) {
+			k = len(x)
+		}
+		s.buf = append(s.buf, x[:k]...)
+		x = x[k:]
+		if len(s.buf) < size {
+			break
+		}
+		for i := range s.frame {
+			s.frame[i] = s.buf[i] * s.window[i]
+		}
+		s.plan.Real(s.spectrum, s.frame)
+		f(s.spectrum)
+		s.buf = s.buf[:copy(s.buf, s.buf[s.hop:])]
+	}
+}
+
+// Reset discards the samples of the incomplete frame.
+func (s *STFT) Reset() {
+	s.buf = s.buf[:0]
+}
@@ -0,0 +1,48 @@
+package fft
+
+import "math"
+
+// Window is a window function. It returns the window of size n.
+//
+// The windows are periodic, which is the right choice for spectral analysis and overlap-add
+// processing.
+type Window func(n int) []float64
+
+// Rectangular returns the rectangular window of size n, which doesn't change the signal at all.
+func Rectangular(n int) []float64 {
+	w := make([]float64, n)
+	for i := range w {
+		w[i] = 1
+	}
+	return w
+}
+
+// Hann returns the Hann window of size n. It's a good default choice.
+func Hann(n int) []float64 {
+	return cosineWindow(n, 0.5, 0.5)
+}
+
+// Hamming returns the Hamming window of size n. Compared to Hann, it has a lower nearest side
+// lobe, but higher far side lobes.
+func Hamming(n int) []float64 {
+	return cosineWindow(n, 0.54, 0.46)
+}
+
+// Blackman returns the Blackman window of size n. Compared to Hann, it has a wider main lobe, but
+// much lower side lobes.
+func Blackman(n int) []float64 {
+	return cosineWindow(n, 0.42, 0.5, 0.08)
+}
+
+// cosineWindow returns the window a[0] - a[1]cos(2πi/n) + a[2]cos(4πi/n) - ...
+func cosineWindow(n int, a ...float64) []float64 {
+	w := make([]float64, n)
+	for i := range w {
+		sign := 1.0
+		for k := range a {
+			w[i] += sign * a[k] * math.Cos(2*math.Pi*float64(k*i)/float64(n))
+			sign = -sign
+		}
+	}
+	return w
+}
@@ -15,6 +15,25 @@ import (
 // Do not close the supplied Reader, instead, use the Close method of the returned
 // StreamSeekCloser when you want to release the resources.
 func Decode(r io.Reader) (s beep.StreamSeekCloser, format beep.Format, err error) {
+	d, format, err := decode(r)
+	if err != nil {
+		return nil, beep.Format{}, err
+	}
+	return d, format, nil
+}
+
+// DecodeMulti is the multichannel counterpart of Decode. It returns a MultiStreamSeekCloser which
+// streams all of the channels of the file. The layout follows the channel assignment of the FLAC
+// specification, which is the same as beep.DefaultLayout.
+func DecodeMulti(r io.Reader) (s beep.MultiStreamSeekCloser, format beep.Format, err error) {
+	d, format, err := decode(r)
+	if err != nil {
+		return nil, beep.Format{}, err
+	}
+	return &multiDecoder{decoder: d, layout: beep.DefaultLayout(format.NumChannels)}, format, nil
+}
+
+func decode(r io.Reader) (dp *decoder, format beep.Format, err error) {
 	d := decoder{r: r}
 	defer func() { // hacky way to always close r if an error occurred
 		if closer, ok := d.r.(io.Closer); ok {
@@ -135,6 +154,65 @@ func (d *decoder) refill() error {
 	return nil
 }
 
+type multiDecoder struct {
+	*decoder
+	layout beep.Layout
+	buf    [][]float64
+}
+
+func (d *multiDecoder) Stream(samples [][]float64) (n int, ok bool) {
+	if d.err != nil {
+		return 0, false
+	}
+	// Copy samples from buffer.
+	j := 0
+	for i := range samples {
+		if j >= len(d.buf) {
+			// refill buffer.
+			if err := d.refill(); err != nil {
+				d.err = err
+				d.pos += n
+				return n, n > 0
+			}
+			j = 0
+		}
+		copy(samples[i], d.buf[j])
+		j++
+		n++
+	}
+	d.buf = d.buf[j:]
+	d.pos += n
+	return n, true
+}
+
+// refill decodes audio samples of all channels to fill the decode buffer.
+func (d *multiDecoder) refill() error {
+	// Parse audio frame.
+	frame, err := d.stream.ParseNext()
+	if err != nil {
+		return err
+	}
+	// Decode audio samples.
+	n := len(frame.Subframes[0].Samples)
+	d.buf = beep.MakeMultiSamples(n, len(frame.Subframes))
+	q := 1 / float64(int32(1)<<(d.stream.Info.BitsPerSample-1))
+	for c, subframe := range frame.Subframes {
+		for i := 0; i < n; i++ {
+			d.buf[i][c] = float64(subframe.Samples[i]) * q
+		}
+	}
+	return nil
+}
+
+func (d *multiDecoder) Seek(p int) error {
+	d.buf = nil
+	return d.decoder.Seek(p)
+}
+
+func (d *multiDecoder) Layout() beep.Layout {
+	return d.layout
+}
+
 func (d *decoder) Err() error {
 	return d.err
 }
@@ -1,24 +1,42 @@
 package beep
 
+import "sync"
+
 // Mixer allows for dynamic mixing of arbitrary number of Streamers. Mixer automatically removes
 // drained Streamers. Mixer's stream never drains, when empty, Mixer streams silence.
 type Mixer struct {
-	streamers []Streamer
+	tracks []*Track
 }
 
-// Len returns the number of Streamers currently playing in the Mixer.
+// Len returns the number of Streamers currently playing in the Mixer. Streamers added together
+// share a Track, but each of them counts until it drains.
 func (m *Mixer) Len() int {
-	return len(m.streamers)
+	n := 0
+	for _, t := range m.tracks {
+		n += len(t.s)
+	}
+	return n
+}
+
+// Add adds Streamers to the Mixer and returns a Track controlling them. When multiple Streamers
+// are added at once, the Track controls all of them together and it drains when all of them
+// drain.
+func (m *Mixer) Add(s ...Streamer) *Track {
+	t := newTrack(s...)
+	m.add(t)
+	return t
 }
 
-// Add adds Streamers to the Mixer.
-func (m *Mixer) Add(s ...Streamer) {
-	m.streamers = append(m.streamers, s...)
+func (m *Mixer) add(t *Track) {
+	m.tracks = append(m.tracks, t)
 }
 
 // Clear removes all Streamers from the mixer.
 func (m *Mixer) Clear() {
-	m.streamers = m.streamers[:0]
+	for _, t := range m.tracks {
+		t.Remove()
+	}
+	m.tracks = m.tracks[:0]
 }
 
 // Stream streams all Streamers currently in the Mixer mixed together. This method always returns
@@ -37,19 +55,15 @@ func (m *Mixer) Stream(samples [][2]float64) (n int, ok bool) {
 			samples[i] = [2]float64{}
 		}
 
-		for si := 0; si < len(m.streamers); si++ {
+		for ti := 0; ti < len(m.tracks); ti++ {
 			// mix the stream
-			sn, sok := m.streamers[si].Stream(tmp[:toStream])
-			for i := range tmp[:sn] {
-				samples[i][0] += tmp[i][0]
-				samples[i][1] += tmp[i][1]
-			}
-			if !sok {
-				// remove drained streamer
-				sj := len(m.streamers) - 1
-				m.streamers[si], m.streamers[sj] = m.streamers[sj], m.streamers[si]
-				m.streamers = m.streamers[:sj]
-				si--
+			tok := m.tracks[ti].mix(samples[:toStream], tmp[:toStream])
+			if !tok {
+				// remove drained track
+				tj := len(m.tracks) - 1
+				m.tracks[ti], m.tracks[tj] = m.tracks[tj], m.tracks[ti]
+				m.tracks = m.tracks[:tj]
+				ti--
 			}
 		}
 
@@ -68,3 +82,127 @@ func (m *Mixer) Stream(samples [][2]float64) (n int, ok bool) {
 func (m *Mixer) Err() error {
 	return nil
 }
+
+// Track is a handle to Streamers added to a Mixer (or played through the speaker). It allows
+// removing, pausing and amplifying them individually.
+//
+// Unlike most of the other types in Beep, all of the methods of Track are safe to call from any
+// goroutine without locking the speaker.
+type Track struct {
+	s []Streamer
+
+	mu       sync.Mutex
+	paused   bool
+	gain     float64
+	pos      int
+	finished bool
+	done     chan struct{}
+}
+
+func newTrack(s ...Streamer) *Track {
+	return &Track{
+		s:    append([]Streamer(nil), s...),
+		done: make(chan struct{}),
+	}
+}
+
+// Remove removes the Track from its Mixer. The Streamers won't be streamed anymore and the Done
+// channel gets closed.
+func (t *Track) Remove() {
+	t.mu.Lock()
+	t.finish()
+	t.mu.Unlock()
+}
+
+// Paused returns whether the Track is paused.
+func (t *Track) Paused() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.paused
+}
+
+// SetPaused pauses or resumes the Track. A paused Track stays in its Mixer, but contributes
+// silence and its Streamers are not streamed.
+func (t *Track) SetPaused(paused bool) {
+	t.mu.Lock()
+	t.paused = paused
+	t.mu.Unlock()
+}
+
+// Gain returns the current gain of the Track.
+func (t *Track) Gain() float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.gain
+}
+
+// SetGain sets the gain of the Track. Same as with effects.Gain, the output of the Track gets
+// multiplied by 1+gain, so the default gain of 0 changes nothing.
+func (t *Track) SetGain(gain float64) {
+	t.mu.Lock()
+	t.gain = gain
+	t.mu.Unlock()
+}
+
+// Position returns the number of samples streamed from the Track so far.
+func (t *Track) Position() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.pos
+}
+
+// Done returns a channel which gets closed when the Track drains or is removed.
+func (t *Track) Done() <-chan struct{} {
+	return t.done
+}
+
+// finish marks the Track as finished. The caller must hold t.mu.
+func (t *Track) finish() {
+	if !t.finished {
+		t.finished = true
+		close(t.done)
+	}
+}
+
+// mix streams the Streamers of the Track into tmp and adds the results to samples. Drained
+// Streamers are removed from the Track. It returns false if all of them are drained or the Track
+// is removed.
+func (t *Track) mix(samples, tmp [][2]float64) bool {
+	t.mu.Lock()
+	finished, paused, gain := t.finished, t.paused, t.gain
+	t.mu.Unlock()
+
+	if finished {
+		return false
+	}
+	if paused {
+		return true
+	}
+
+	n := 0
+	for si := 0; si < len(t.s); si++ {
+		sn, sok := t.s[si].Stream(tmp)
+		for i := range tmp[:sn] {
+			samples[i][0] += tmp[i][0] * (1 + gain)
+			samples[i][1] += tmp[i][1] * (1 + gain)
+		}
+		if sn > n {
+			n = sn
+		}
+		if !sok {
+			// remove drained streamer
+			sj := len(t.s) - 1
+			t.s[si], t.s[sj] = t.s[sj], t.s[si]
+			t.s = t.s[:sj]
+			si--
+		}
+	}
+
+	t.mu.Lock()
+	t.pos += n
+	if len(t.s) == 0 {
+		t.finish()
+	}
+	t.mu.Unlock()
+	return len(t.s) > 0
+}
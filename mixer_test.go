@@ -0,0 +1,122 @@
+package beep_test
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/faiface/beep"
+)
+
+func TestMixerTrack(t *testing.T) {
+	s, data := randomDataStreamer(1000)
+
+	var m beep.Mixer
+	track := m.Add(s)
+
+	buf := make([][2]float64, 300)
+	m.Stream(buf)
+	if !reflect.DeepEqual(data[:300], buf) || track.Position() != 300 {
+		t.Fatal("Track not streamed correctly")
+	}
+
+	track.SetPaused(true)
+	m.Stream(buf)
+	if !reflect.DeepEqual(make([][2]float64, 300), buf) || track.Position() != 300 {
+		t.Fatal("paused Track not silent")
+	}
+
+	track.SetPaused(false)
+	track.SetGain(1)
+	m.Stream(buf)
+	for i := range buf {
+		if buf[i][0] != 2*data[300+i][0] || buf[i][1] != 2*data[300+i][1] {
+			t.Fatal("Track gain not applied")
+		}
+	}
+
+	track.Remove()
+	<-track.Done()
+	m.Stream(buf)
+	if m.Len() != 0 || track.Position() != 600 {
+		t.Fatal("removed Track still in the Mixer")
+	}
+
+	drained := m.Add(beep.Silence(10))
+	m.Stream(buf)
+	m.Stream(buf)
+	select {
+	case <-drained.Done():
+	default:
+		t.Fatal("Done not closed after the Track drained")
+	}
+}
+
+func TestMixerTrackStreamers(t *testing.T) {
+	s1, data1 := randomDataStreamer(300)
+	s2, data2 := randomDataStreamer(500)
+
+	var m beep.Mixer
+	track := m.Add(s1, s2)
+	if m.Len() != 2 {
+		t.Fatalf("Mixer length %d with a Track of 2 Streamers, expected 2", m.Len())
+	}
+
+	buf := make([][2]float64, 400)
+	m.Stream(buf)
+	for i := range buf {
+		want := data2[i]
+		if i < len(data1) {
+			want[0] += data1[i][0]
+			want[1] += data1[i][1]
+		}
+		if buf[i] != want {
+			t.Fatalf("Track of 2 Streamers sample %d: expected: %v, actual: %v", i, want, buf[i])
+		}
+	}
+	m.Stream(buf)
+	if m.Len() != 1 || track.Position() != 500 {
+		t.Fatalf("Mixer length %d and Track position %d after the first Streamer drained, expected 1 and 500", m.Len(), track.Position())
+	}
+
+	m.Stream(buf)
+	if m.Len() != 0 {
+		t.Fatalf("Mixer length %d after both Streamers drained, expected 0", m.Len())
+	}
+	select {
+	case <-track.Done():
+	default:
+		t.Fatal("Done not closed after all of the Streamers of the Track drained")
+	}
+}
+
+func TestTimeline(t *testing.T) {
+	var tl beep.Timeline
+
+	s1, data1 := randomDataStreamer(100)
+	s2, data2 := randomDataStreamer(100)
+	tl.Schedule(137, s1)
+	tl.Schedule(1000, s2)
+	removed := tl.Schedule(50, beep.Silence(-1))
+	removed.Remove()
+
+	got := make([][2]float64, 1234)
+	for buf := got; len(buf) > 0; {
+		n := rand.Intn(300) + 1
+		if n > len(buf) {
+			n = len(buf)
+		}
+		tl.Stream(buf[:n])
+		buf = buf[n:]
+	}
+
+	want := make([][2]float64, 1234)
+	copy(want[137:], data1)
+	copy(want[1000:], data2)
+	if !reflect.DeepEqual(want, got) {
+		t.Error("Timeline didn't start the Streamers at the scheduled positions")
+	}
+	if tl.Position() != 1234 || tl.Pending() != 0 {
+		t.Error("Timeline position or pending count incorrect")
+	}
+}
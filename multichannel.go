@@ -0,0 +1,489 @@
+package beep
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+// Channel is a single speaker position. The values match the bits of the WAVE_FORMAT_EXTENSIBLE
+// channel mask, so a Channel can be directly or-ed into a Layout.
+type Channel uint32
+
+// Speaker positions known to Beep.
+const (
+	FrontLeft Channel = 1 << iota
+	FrontRight
+	FrontCenter
+	LowFrequency
+	BackLeft
+	BackRight
+	FrontLeftOfCenter
+	FrontRightOfCenter
+	BackCenter
+	SideLeft
+	SideRight
+	TopCenter
+	TopFrontLeft
+	TopFrontCenter
+	TopFrontRight
+	TopBackLeft
+	TopBackCenter
+	TopBackRight
+)
+
+// Layout is a set of channels. The channels of a multichannel sample are always ordered by their
+// Channel value, which is the same order as in WAVE and FLAC files.
+type Layout uint32
+
+// Commonly used channel layouts.
+const (
+	LayoutMono     = Layout(FrontCenter)
+	LayoutStereo   = Layout(FrontLeft | FrontRight)
+	LayoutQuad     = Layout(FrontLeft | FrontRight | BackLeft | BackRight)
+	Layout5Point1  = Layout(FrontLeft | FrontRight | FrontCenter | LowFrequency | BackLeft | BackRight)
+	Layout7Point1  = Layout5Point1 | Layout(SideLeft|SideRight)
+	layoutAllKnown = Layout(TopBackRight<<1 - 1)
+)
+
+// DefaultLayout returns the layout conventionally used for numChannels channels when no explicit
+// layout is available. The conventions follow the FLAC specification. For more than 8 channels,
+// the channels are simply assigned in order of their Channel values.
+//
+// DefaultLayout panics if numChannels is not between 1 and 18.
+func DefaultLayout(numChannels int) Layout {
+	switch numChannels {
+	case 1:
+		return LayoutMono
+	case 2:
+		return LayoutStereo
+	case 3:
+		return LayoutStereo | Layout(FrontCenter)
+	case 4:
+		return LayoutQuad
+	case 5:
+		return LayoutQuad | Layout(FrontCenter)
+	case 6:
+		return Layout5Point1
+	case 7:
+		return Layout5Point1&^Layout(BackLeft|BackRight) | Layout(BackCenter|SideLeft|SideRight)
+	case 8:
+		return Layout7Point1
+	}
+	if numChannels < 1 || numChannels > bits.OnesCount32(uint32(layoutAllKnown)) {
+		panic(fmt.Errorf("layout: invalid number of channels: %d", numChannels))
+	}
+	return Layout(1<<uint(numChannels) - 1)
+}
+
+// NumChannels returns the number of channels in the Layout.
+func (l Layout) NumChannels() int {
+	return bits.OnesCount32(uint32(l))
+}
+
+// Channels returns the channels of the Layout in the order in which they appear in a sample.
+func (l Layout) Channels() []Channel {
+	channels := make([]Channel, 0, l.NumChannels())
+	for rest := uint32(l); rest != 0; rest &= rest - 1 {
+		channels = append(channels, Channel(rest&-rest))
+	}
+	return channels
+}
+
+// Index returns the index of the channel c in a sample of this Layout, or -1 if the Layout does
+// not contain c.
+func (l Layout) Index(c Channel) int {
+	if uint32(l)&uint32(c) == 0 {
+		return -1
+	}
+	return bits.OnesCount32(uint32(l) & (uint32(c) - 1))
+}
+
+// MultiStreamer is the multichannel counterpart of Streamer. It streams samples with an arbitrary
+// number of channels described by its Layout.
+//
+// All the rules of the Streamer interface apply to MultiStreamer as well.
+type MultiStreamer interface {
+	// Stream copies at most len(samples) next audio samples to the samples slice.
+	//
+	// Each samples[i] must have exactly Layout().NumChannels() elements. The value at
+	// samples[i][c] is the value of the c-th channel of the Layout in the i-th sample. Use
+	// MakeMultiSamples to allocate such a slice.
+	Stream(samples [][]float64) (n int, ok bool)
+
+	// Err returns an error which occurred during streaming. If no error occurred, nil is
+	// returned.
+	Err() error
+
+	// Layout returns the channel layout of the streamed samples. It must never change.
+	Layout() Layout
+}
+
+// MultiStreamSeeker is a finite duration MultiStreamer which supports seeking to an arbitrary
+// position.
+type MultiStreamSeeker interface {
+	MultiStreamer
+	Len() int
+	Position() int
+	Seek(p int) error
+}
+
+// MultiStreamSeekCloser is a union of MultiStreamSeeker and a Close method.
+type MultiStreamSeekCloser interface {
+	MultiStreamSeeker
+	Close() error
+}
+
+// MakeMultiSamples allocates n samples of numChannels channels each. All of the samples share one
+// underlying array.
+func MakeMultiSamples(n, numChannels int) [][]float64 {
+	data := make([]float64, n*numChannels)
+	samples := make([][]float64, n)
+	for i := range samples {
+		samples[i] = data[i*numChannels : (i+1)*numChannels : (i+1)*numChannels]
+	}
+	return samples
+}
+
+// Multi converts a stereo Streamer into a MultiStreamer with the LayoutStereo layout.
+//
+// The returned MultiStreamer propagates s's errors through Err.
+func Multi(s Streamer) MultiStreamer {
+	return &multi{s: s}
+}
+
+type multi struct {
+	s   Streamer
+	tmp [512][2]float64
+}
+
+func (m *multi) Stream(samples [][]float64) (n int, ok bool) {
+	for len(samples) > 0 {
+		toStream := len(m.tmp)
+		if toStream > len(samples) {
+			toStream = len(samples)
+		}
+		sn, sok := m.s.Stream(m.tmp[:toStream])
+		for i := range m.tmp[:sn] {
+			samples[i][0] = m.tmp[i][0]
+			samples[i][1] = m.tmp[i][1]
+		}
+		n, ok = n+sn, ok || sok
+		if sn < toStream {
+			break
+		}
+		samples = samples[sn:]
+	}
+	return n, ok
+}
+
+func (m *multi) Err() error {
+	return m.s.Err()
+}
+
+func (m *multi) Layout() Layout {
+	return LayoutStereo
+}
+
+// Downmix converts a MultiStreamer into a stereo Streamer.
+//
+// Mono is copied into both channels. Otherwise, left channels go to the left, right channels go to
+// the right, and center and surround channels are attenuated by 3dB (ITU-R BS.775). Center
+// channels go to both sides. The low frequency channel is dropped.
+//
+// The returned Streamer propagates m's errors through Err.
+func Downmix(m MultiStreamer) Streamer {
+	channels := m.Layout().Channels()
+	gains := make([][2]float64, len(channels))
+	if len(channels) == 1 {
+		gains[0] = [2]float64{1, 1}
+	} else {
+		for i, c := range channels {
+			gains[i] = downmixGain(c)
+		}
+	}
+	return &downmix{
+		m:     m,
+		gains: gains,
+		tmp:   MakeMultiSamples(512, len(channels)),
+	}
+}
+
+func downmixGain(c Channel) [2]float64 {
+	const att = math.Sqrt2 / 2
+	switch c {
+	case FrontLeft, FrontLeftOfCenter:
+		return [2]float64{1, 0}
+	case FrontRight, FrontRightOfCenter:
+		return [2]float64{0, 1}
+	case BackLeft, SideLeft, TopFrontLeft, TopBackLeft:
+		return [2]float64{att, 0}
+	case BackRight, SideRight, TopFrontRight, TopBackRight:
+		return [2]float64{0, att}
+	case FrontCenter, BackCenter, TopCenter, TopFrontCenter, TopBackCenter:
+		return [2]float64{att, att}
+	}
+	return [2]float64{}
+}
+
+type downmix struct {
+	m     MultiStreamer
+	gains [][2]float64
+	tmp   [][]float64
+}
+
+func (d *downmix) Stream(samples [][2]float64) (n int, ok bool) {
+	for len(samples) > 0 {
+		toStream := len(d.tmp)
+		if toStream > len(samples) {
+			toStream = len(samples)
+		}
+		sn, sok := d.m.Stream(d.tmp[:toStream])
+		for i := range d.tmp[:sn] {
+			var l, r float64
+			for c, x := range d.tmp[i] {
+				l += d.gains[c][0] * x
+				r += d.gains[c][1] * x
+			}
+			samples[i] = [2]float64{l, r}
+		}
+		n, ok = n+sn, ok || sok
+		if sn < toStream {
+			break
+		}
+		samples = samples[sn:]
+	}
+	return n, ok
+}
+
+func (d *downmix) Err() error {
+	return d.m.Err()
+}
+
+// Remap converts a MultiStreamer into a MultiStreamer with a different layout. Channels present in
+// both layouts are copied, channels missing in the source layout are silent and channels missing
+// in the target layout are dropped.
+//
+// The returned MultiStreamer propagates m's errors through Err.
+func Remap(layout Layout, m MultiStreamer) MultiStreamer {
+	if m.Layout() == layout {
+		return m
+	}
+	from := m.Layout()
+	channels := layout.Channels()
+	index := make([]int, len(channels))
+	for i, c := range channels {
+		index[i] = from.Index(c)
+	}
+	return &remap{
+		m:      m,
+		layout: layout,
+		index:  index,
+		tmp:    MakeMultiSamples(512, from.NumChannels()),
+	}
+}
+
+type remap struct {
+	m      MultiStreamer
+	layout Layout
+	index  []int
+	tmp    [][]float64
+}
+
+func (r *remap) Stream(samples [][]float64) (n int, ok bool) {
+	for len(samples) > 0 {
+		toStream := len(r.tmp)
+		if toStream > len(samples) {
+			toStream = len(samples)
+		}
+		sn, sok := r.m.Stream(r.tmp[:toStream])
+		for i := range r.tmp[:sn] {
+			for c, j := range r.index {
+				if j < 0 {
+					samples[i][c] = 0
+					continue
+				}
+				samples[i][c] = r.tmp[i][j]
+			}
+		}
+		n, ok = n+sn, ok || sok
+		if sn < toStream {
+			break
+		}
+		samples = samples[sn:]
+	}
+	return n, ok
+}
+
+func (r *remap) Err() error {
+	return r.m.Err()
+}
+
+func (r *remap) Layout() Layout {
+	return r.layout
+}
+
+// TakeMulti returns a MultiStreamer which streams at most num samples from m.
+//
+// The returned MultiStreamer propagates m's errors through Err.
+func TakeMulti(num int, m MultiStreamer) MultiStreamer {
+	return &takeMulti{
+		m:       m,
+		remains: num,
+	}
+}
+
+type takeMulti struct {
+	m       MultiStreamer
+	remains int
+}
+
+func (t *takeMulti) Stream(samples [][]float64) (n int, ok bool) {
+	if t.remains <= 0 {
+		return 0, false
+	}
+	toStream := t.remains
+	if len(samples) < toStream {
+		toStream = len(samples)
+	}
+	n, ok = t.m.Stream(samples[:toStream])
+	t.remains -= n
+	return n, ok
+}
+
+func (t *takeMulti) Err() error {
+	return t.m.Err()
+}
+
+func (t *takeMulti) Layout() Layout {
+	return t.m.Layout()
+}
+
+// SeqMulti takes zero or more MultiStreamers and returns a MultiStreamer which streams them one by
+// one without pauses. All of the MultiStreamers are remapped to the layout of the first one. If
+// there are no MultiStreamers, the layout is LayoutStereo.
+//
+// SeqMulti does not propagate errors from the MultiStreamers.
+func SeqMulti(m ...MultiStreamer) MultiStreamer {
+	layout := LayoutStereo
+	if len(m) > 0 {
+		layout = m[0].Layout()
+	}
+	remapped := make([]MultiStreamer, len(m))
+	for i := range m {
+		remapped[i] = Remap(layout, m[i])
+	}
+	return &seqMulti{m: remapped, layout: layout}
+}
+
+type seqMulti struct {
+	m      []MultiStreamer
+	i      int
+	layout Layout
+}
+
+func (s *seqMulti) Stream(samples [][]float64) (n int, ok bool) {
+	for s.i < len(s.m) && len(samples) > 0 {
+		sn, sok := s.m[s.i].Stream(samples)
+		samples = samples[sn:]
+		n, ok = n+sn, ok || sok
+		if !sok {
+			s.i++
+		}
+	}
+	return n, ok
+}
+
+func (s *seqMulti) Err() error {
+	return nil
+}
+
+func (s *seqMulti) Layout() Layout {
+	return s.layout
+}
+
+// MultiMixer is the multichannel counterpart of Mixer. It mixes an arbitrary number of
+// MultiStreamers into its own layout. MultiMixer automatically removes drained MultiStreamers.
+// MultiMixer's stream never drains, when empty, MultiMixer streams silence.
+type MultiMixer struct {
+	layout    Layout
+	streamers []MultiStreamer
+	tmp       [][]float64
+}
+
+// NewMultiMixer creates a new empty MultiMixer which mixes into the provided layout.
+func NewMultiMixer(layout Layout) *MultiMixer {
+	return &MultiMixer{
+		layout: layout,
+		tmp:    MakeMultiSamples(512, layout.NumChannels()),
+	}
+}
+
+// Len returns the number of MultiStreamers currently playing in the MultiMixer.
+func (m *MultiMixer) Len() int {
+	return len(m.streamers)
+}
+
+// Add adds MultiStreamers to the MultiMixer. MultiStreamers with a different layout are remapped to
+// the MultiMixer's layout.
+func (m *MultiMixer) Add(s ...MultiStreamer) {
+	for _, st := range s {
+		m.streamers = append(m.streamers, Remap(m.layout, st))
+	}
+}
+
+// Clear removes all MultiStreamers from the MultiMixer.
+func (m *MultiMixer) Clear() {
+	m.streamers = m.streamers[:0]
+}
+
+// Stream streams all MultiStreamers currently in the MultiMixer mixed together. This method always
+// returns len(samples), true. If there are no MultiStreamers available, this methods streams
+// silence.
+func (m *MultiMixer) Stream(samples [][]float64) (n int, ok bool) {
+	for len(samples) > 0 {
+		toStream := len(m.tmp)
+		if toStream > len(samples) {
+			toStream = len(samples)
+		}
+
+		// clear the samples
+		for i := range samples[:toStream] {
+			for c := range samples[i] {
+				samples[i][c] = 0
+			}
+		}
+
+		for si := 0; si < len(m.streamers); si++ {
+			// mix the stream
+			sn, sok := m.streamers[si].Stream(m.tmp[:toStream])
+			for i := range m.tmp[:sn] {
+				for c, x := range m.tmp[i] {
+					samples[i][c] += x
+				}
+			}
+			if !sok {
+				// remove drained streamer
+				sj := len(m.streamers) - 1
+				m.streamers[si], m.streamers[sj] = m.streamers[sj], m.streamers[si]
+				m.streamers = m.streamers[:sj]
+				si--
+			}
+		}
+
+		samples = samples[toStream:]
+		n += toStream
+	}
+
+	return n, true
+}
+
+// Err always returns nil for MultiMixer. See Mixer.Err for the reasons.
+func (m *MultiMixer) Err() error {
+	return nil
+}
+
+// Layout returns the layout the MultiMixer mixes into.
+func (m *MultiMixer) Layout() Layout {
+	return m.layout
+}
@@ -0,0 +1,92 @@
+package beep_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/faiface/beep"
+)
+
+// collectMulti drains MultiStreamer m and returns all of the samples it streamed.
+func collectMulti(m beep.MultiStreamer) [][]float64 {
+	var (
+		result [][]float64
+		buf    = beep.MakeMultiSamples(479, m.Layout().NumChannels())
+	)
+	for {
+		n, ok := m.Stream(buf)
+		if !ok {
+			return result
+		}
+		for _, sample := range buf[:n] {
+			result = append(result, append([]float64(nil), sample...))
+		}
+	}
+}
+
+func TestLayout(t *testing.T) {
+	for n := 1; n <= 18; n++ {
+		if got := beep.DefaultLayout(n).NumChannels(); got != n {
+			t.Fatalf("DefaultLayout(%d) has %d channels", n, got)
+		}
+	}
+
+	want := []beep.Channel{beep.FrontLeft, beep.FrontRight, beep.FrontCenter, beep.LowFrequency, beep.BackLeft, beep.BackRight}
+	if got := beep.Layout5Point1.Channels(); !reflect.DeepEqual(want, got) {
+		t.Fatalf("Layout5Point1 channels: expected: %v, actual: %v", want, got)
+	}
+	for i, c := range want {
+		if got := beep.Layout5Point1.Index(c); got != i {
+			t.Fatalf("Layout5Point1 index of %v: expected: %v, actual: %v", c, i, got)
+		}
+	}
+	if got := beep.LayoutStereo.Index(beep.FrontCenter); got != -1 {
+		t.Fatalf("LayoutStereo index of a missing channel: expected: -1, actual: %v", got)
+	}
+}
+
+func TestMultiDownmix(t *testing.T) {
+	s, data := randomDataStreamer(12345)
+	got := collect(beep.Downmix(beep.Multi(s)))
+	if !reflect.DeepEqual(data, got) {
+		t.Error("Downmix of Multi does not reproduce the original stereo samples")
+	}
+}
+
+func TestRemap(t *testing.T) {
+	s, data := randomDataStreamer(1000)
+	got := collectMulti(beep.Remap(beep.Layout5Point1, beep.Multi(s)))
+	if len(got) != len(data) {
+		t.Fatalf("Remap streamed %d samples, expected %d", len(got), len(data))
+	}
+	for i := range got {
+		want := []float64{data[i][0], data[i][1], 0, 0, 0, 0}
+		if !reflect.DeepEqual(want, got[i]) {
+			t.Fatalf("Remap sample %d: expected: %v, actual: %v", i, want, got[i])
+		}
+	}
+}
+
+func TestBufferMulti(t *testing.T) {
+	s, data := randomDataStreamer(1000)
+	format := beep.Format{SampleRate: 44100, NumChannels: 6, Precision: 3}
+	b := beep.NewBuffer(format)
+	b.AppendMulti(beep.Remap(beep.Layout5Point1, beep.Multi(s)))
+	if b.Len() != len(data) {
+		t.Fatalf("buffer length isn't equal to appended stream length: expected: %v, actual: %v", len(data), b.Len())
+	}
+
+	deviation := 2.0 / (math.Pow(2, float64(format.Precision)*8) - 2)
+	got := collectMulti(beep.TakeMulti(b.Len(), b.MultiStreamer(beep.Layout5Point1, 0, b.Len())))
+	for i := range got {
+		if math.Abs(got[i][0]-data[i][0]) > deviation || math.Abs(got[i][1]-data[i][1]) > deviation {
+			t.Fatalf("decoded sample is too different: %v -> %v (deviation: %v)", data[i], got[i], deviation)
+		}
+		for _, x := range got[i][2:] {
+			if x != 0 {
+				t.Fatalf("silent channel decoded as %v", x)
+			}
+		}
+	}
+}
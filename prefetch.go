@@ -0,0 +1,243 @@
+package beep
+
+import (
+	"fmt"
+	"sync"
+)
+
+// prefetchChunk is the number of samples the Prefetcher reads from the original Streamer at once.
+const prefetchChunk = 1024
+
+// Prefetch returns a Prefetcher which reads s ahead on its own goroutine, keeping at least size
+// samples ready to be streamed.
+//
+// Decoders read and decode files in their Stream method, which normally runs under the speaker's
+// lock. With slow disks or network filesystems, this can cause glitches in playback. Prefetch moves
+// this work out of the way.
+//
+//   streamer, format, err := mp3.Decode(f)
+//   // ...
+//   prefetcher := beep.Prefetch(format.SampleRate.N(time.Second), streamer)
+//   defer prefetcher.Close()
+//   speaker.Play(prefetcher)
+//
+// The original Streamer must not be used by anything else after being passed to Prefetch.
+//
+// The returned Prefetcher propagates s's errors once all the samples streamed before the error got
+// streamed.
+func Prefetch(size int, s Streamer) *Prefetcher {
+	numChunks := (size + prefetchChunk - 1) / prefetchChunk
+	if numChunks < 1 {
+		numChunks = 1
+	}
+	p := &Prefetcher{
+		s:      s,
+		chunks: make(chan chunk, numChunks),
+		free:   make(chan [][2]float64, numChunks),
+	}
+	for i := 0; i < numChunks; i++ {
+		p.free <- make([][2]float64, prefetchChunk)
+	}
+	if ss, ok := s.(StreamSeeker); ok {
+		p.pos = ss.Position()
+	}
+	p.start()
+	return p
+}
+
+// Prefetcher is a Streamer created by Prefetch. If the original Streamer is a StreamSeeker,
+// Prefetcher can be used as a StreamSeeker too. Seeking flushes the prefetched samples. If the
+// original Streamer has a Close method, Prefetcher closes it on Close.
+type Prefetcher struct {
+	mu sync.Mutex // guards s, which is used by the prefetching goroutine
+	s  Streamer
+
+	chunks  chan chunk        // prefetched chunks
+	free    chan [][2]float64 // buffers available for prefetching
+	quit    chan struct{}
+	done    chan struct{}
+	running bool
+
+	cur     [][2]float64 // remaining samples of the chunk being streamed
+	curBuf  [][2]float64 // buffer of the chunk being streamed
+	drained bool
+	err     error
+	pos     int
+	closed  bool
+}
+
+type chunk struct {
+	buf [][2]float64
+	n   int
+	ok  bool
+	err error
+}
+
+// start starts the prefetching goroutine.
+func (p *Prefetcher) start() {
+	p.quit = make(chan struct{})
+	p.done = make(chan struct{})
+	p.running = true
+	go p.prefetch(p.quit, p.done)
+}
+
+// stop stops the prefetching goroutine and waits until it exits.
+func (p *Prefetcher) stop() {
+	if p.running {
+		close(p.quit)
+		<-p.done
+		p.running = false
+	}
+}
+
+func (p *Prefetcher) prefetch(quit, done chan struct{}) {
+	defer close(done)
+	for {
+		var buf [][2]float64
+		select {
+		case buf = <-p.free:
+		case <-quit:
+			return
+		}
+
+		c := chunk{buf: buf, ok: true}
+		p.mu.Lock()
+		for c.n < len(buf) {
+			sn, sok := p.s.Stream(buf[c.n:])
+			c.n += sn
+			if !sok {
+				c.ok = false
+				c.err = p.s.Err()
+				break
+			}
+		}
+		p.mu.Unlock()
+
+		select {
+		case p.chunks <- c:
+		case <-quit:
+			p.free <- buf
+			return
+		}
+		if !c.ok {
+			return
+		}
+	}
+}
+
+// Stream streams the prefetched samples. If the prefetching falls behind, Stream waits for it.
+func (p *Prefetcher) Stream(samples [][2]float64) (n int, ok bool) {
+	for len(samples) > 0 {
+		if len(p.cur) == 0 {
+			if p.curBuf != nil {
+				p.free <- p.curBuf
+				p.curBuf = nil
+			}
+			if p.drained || p.closed {
+				break
+			}
+			c := <-p.chunks
+			p.curBuf, p.cur = c.buf, c.buf[:c.n]
+			if !c.ok {
+				p.drained = true
+				p.err = c.err
+			}
+			continue
+		}
+		cn := copy(samples, p.cur)
+		p.cur = p.cur[cn:]
+		samples = samples[cn:]
+		p.pos += cn
+		n += cn
+	}
+	return n, n > 0
+}
+
+// Err propagates the original Streamer's errors.
+func (p *Prefetcher) Err() error {
+	if len(p.cur) > 0 {
+		return nil
+	}
+	return p.err
+}
+
+// Len returns the total number of samples of the original Streamer. If the original Streamer is
+// not a StreamSeeker, Len returns 0.
+func (p *Prefetcher) Len() int {
+	ss, ok := p.s.(StreamSeeker)
+	if !ok {
+		return 0
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return ss.Len()
+}
+
+// Position returns the position of the next sample streamed by the Prefetcher.
+func (p *Prefetcher) Position() int {
+	return p.pos
+}
+
+// Seek flushes the prefetched samples and seeks the original Streamer. If the original Streamer is
+// not a StreamSeeker, Seek returns an error.
+func (p *Prefetcher) Seek(pos int) error {
+	ss, ok := p.s.(StreamSeeker)
+	if !ok {
+		return fmt.Errorf("prefetch: seek: original Streamer is not a StreamSeeker")
+	}
+	if p.closed {
+		return fmt.Errorf("prefetch: seek: closed")
+	}
+
+	p.stop()
+	p.flush()
+	err := ss.Seek(pos)
+	if err == nil {
+		p.pos = pos
+		p.drained = false
+		p.err = nil
+	} else {
+		// the prefetched samples are gone, continue right where the streaming stopped
+		err = fmt.Errorf("prefetch: %v", err)
+		if serr := ss.Seek(p.pos); serr == nil {
+			p.drained = false
+		} else {
+			p.drained = true
+		}
+	}
+	if !p.drained {
+		p.start()
+	}
+	return err
+}
+
+// flush returns all the prefetched buffers back for reuse.
+func (p *Prefetcher) flush() {
+	p.cur = nil
+	if p.curBuf != nil {
+		p.free <- p.curBuf
+		p.curBuf = nil
+	}
+	for {
+		select {
+		case c := <-p.chunks:
+			p.free <- c.buf
+		default:
+			return
+		}
+	}
+}
+
+// Close stops the prefetching and closes the original Streamer, if it has a Close method.
+func (p *Prefetcher) Close() error {
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+	p.stop()
+	p.flush()
+	if sc, ok := p.s.(StreamCloser); ok {
+		return sc.Close()
+	}
+	return nil
+}
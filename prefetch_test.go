@@ -0,0 +1,32 @@
+package beep_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/faiface/beep"
+)
+
+func TestPrefetch(t *testing.T) {
+	s, data := randomDataStreamer(54321)
+	p := beep.Prefetch(5000, s)
+	defer p.Close()
+
+	if got := collect(p); !reflect.DeepEqual(data, got) {
+		t.Fatal("Prefetch not working correctly")
+	}
+	if p.Len() != len(data) || p.Position() != len(data) {
+		t.Fatal("Prefetch length or position not correct")
+	}
+
+	for _, pos := range []int{12345, 0, 50000} {
+		buf := make([][2]float64, 3000)
+		p.Stream(buf)
+		if err := p.Seek(pos); err != nil {
+			t.Fatal(err)
+		}
+		if got := collect(p); !reflect.DeepEqual(data[pos:], got) {
+			t.Fatalf("Prefetch not correct after seeking to %d", pos)
+		}
+	}
+}
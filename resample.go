@@ -1,6 +1,9 @@
 package beep
 
-import "fmt"
+import (
+	"fmt"
+	"math"
+)
 
 // Resample takes a Streamer which is assumed to stream at the old sample rate and returns a
 // Streamer, which streams the data from the original Streamer resampled to the new sample rate.
@@ -31,6 +34,9 @@ import "fmt"
 // Sane quality values are usually below 16. Higher values will consume too much CPU, giving
 // negligible quality improvements.
 //
+// The returned Resampler has the methods of a StreamSeeker even if s is not one, but then it
+// can't seek and its length is 0, see Seekable.
+//
 // Resample propagates errors from s.
 func Resample(quality int, old, new SampleRate, s Streamer) *Resampler {
 	return ResampleRatio(quality, float64(old)/float64(new), s)
@@ -40,32 +46,46 @@ func Resample(quality int, old, new SampleRate, s Streamer) *Resampler {
 // specifically, the old sample rate divided by the new sample rate. Aside from correcting the
 // sample rate, this can be used to change the speed of the audio. For example, resampling at the
 // ratio of 2 and playing at the original sample rate will cause doubled speed in playback.
+//
+// A negative ratio plays the audio backwards. This requires s to be a StreamSeeker, otherwise the
+// Resampler drains as soon as it runs out of the original data it has buffered.
 func ResampleRatio(quality int, ratio float64, s Streamer) *Resampler {
 	if quality < 1 || 64 < quality {
 		panic(fmt.Errorf("resample: invalid quality: %d", quality))
 	}
 	return &Resampler{
-		s:     s,
-		ratio: ratio,
-		first: true,
-		buf1:  make([][2]float64, 512),
-		buf2:  make([][2]float64, 512),
-		pts:   make([]point, quality*2),
-		off:   0,
-		pos:   0,
+		s:      s,
+		ratio:  ratio,
+		target: ratio,
+		first:  true,
+		buf1:   make([][2]float64, 512),
+		buf2:   make([][2]float64, 512),
+		pts:    make([]point, quality*2),
+		off:    0,
+		pos:    0,
 	}
 }
 
 // Resampler is a Streamer created by Resample and ResampleRatio functions. It allows dynamic
 // changing of the resampling ratio, which can be useful for dynamically changing the speed of
 // streaming.
+//
+// If the original Streamer is a StreamSeeker, Resampler can be used as a StreamSeeker too. Lengths
+// and positions are mapped through the absolute value of the current ratio. Resampler always
+// implements the StreamSeeker interface though, so a type assertion can't tell whether it can
+// seek. If the original Streamer is not a StreamSeeker, Len returns 0 and Seek returns an error,
+// use Seekable to check.
 type Resampler struct {
 	s          Streamer     // the orignal streamer
 	ratio      float64      // old sample rate / new sample rate
+	target     float64      // target is the ratio at the end of the current glide
+	glide      int          // glide is the number of samples until ratio reaches target, -1 means the next buffer
 	first      bool         // true when Stream was not called before
 	buf1, buf2 [][2]float64 // buf1 contains previous buf2, new data goes into buf2, buf1 is because interpolation might require old samples
 	pts        []point      // pts is for points used for interpolation
 	off        int          // off is the position of the start of buf2 in the original data
+	j0         float64      // j0 is the position in the original data at pos0
+	pos0       int          // pos0 is the position in the resampled data where the ratio last changed
 	pos        int          // pos is the current position in the resampled data
 }
 
@@ -77,13 +97,19 @@ func (r *Resampler) Stream(samples [][2]float64) (n int, ok bool) {
 		r.buf2 = r.buf2[:sn]
 		r.first = false
 	}
+	if r.glide < 0 {
+		r.glide = len(samples)
+	}
 	// we start resampling, sample by sample
 	for len(samples) > 0 {
+		// calculate the current position in the original data
+		j := r.j0 + float64(r.pos-r.pos0)*r.ratio
+		if j < 0 {
+			// we got to the beginning while playing backwards
+			return n, n > 0
+		}
 	again:
 		for c := range samples[0] {
-			// calculate the current position in the original data
-			j := float64(r.pos) * r.ratio
-
 			// find quality*2 closest samples to j and translate them to points for interpolation
 			for pi := range r.pts {
 				// calculate the index of one of the closest samples
@@ -91,6 +117,13 @@ func (r *Resampler) Stream(samples [][2]float64) (n int, ok bool) {
 
 				var y float64
 				switch {
+				// the sample is before buf1, this only happens when playing backwards
+				case k < r.off-len(r.buf1):
+					ss, ok := r.s.(StreamSeeker)
+					if !ok || r.load(ss, int(j)) != nil {
+						return n, n > 0
+					}
+					goto again
 				// the sample is in buf1
 				case k < r.off:
 					y = r.buf1[len(r.buf1)+k-r.off][c]
@@ -132,24 +165,127 @@ func (r *Resampler) Stream(samples [][2]float64) (n int, ok bool) {
 		samples = samples[1:]
 		n++
 		r.pos++
+
+		// move the ratio towards the target, keeping the position in the original data continuous
+		if r.glide > 0 {
+			r.j0, r.pos0 = j+r.ratio, r.pos
+			r.ratio += (r.target - r.ratio) / float64(r.glide)
+			r.glide--
+		}
 	}
 	return n, true
 }
 
+// load loads the original data around the position from, so that from is at the start of buf2.
+func (r *Resampler) load(ss StreamSeeker, from int) error {
+	start := from - cap(r.buf1)
+	if start > ss.Len() {
+		start = ss.Len()
+	}
+	skip := 0
+	if start < 0 {
+		skip, start = -start, 0
+	}
+	if err := ss.Seek(start); err != nil {
+		return err
+	}
+	r.buf1 = r.buf1[:cap(r.buf1)]
+	for i := range r.buf1[:skip] {
+		r.buf1[i] = [2]float64{}
+	}
+	streamFull(ss, r.buf1[skip:])
+	r.buf2 = r.buf2[:cap(r.buf2)]
+	sn := 0
+	for sn < len(r.buf2) {
+		n, ok := ss.Stream(r.buf2[sn:])
+		sn += n
+		if !ok || n == 0 {
+			break
+		}
+	}
+	r.buf2 = r.buf2[:sn]
+	r.off = from
+	r.first = false
+	return nil
+}
+
 // Err propagates the original Streamer's errors.
 func (r *Resampler) Err() error {
 	return r.s.Err()
 }
 
-// Ratio returns the current resampling ratio.
+// Seekable reports whether the original Streamer is a StreamSeeker, that is, whether the Resampler
+// can seek and knows its length.
+func (r *Resampler) Seekable() bool {
+	_, ok := r.s.(StreamSeeker)
+	return ok
+}
+
+// Len returns the total number of samples of the resampled data. If the original Streamer is not
+// a StreamSeeker or the ratio is zero, Len returns 0.
+func (r *Resampler) Len() int {
+	ss, ok := r.s.(StreamSeeker)
+	if !ok || r.ratio == 0 {
+		return 0
+	}
+	return int(math.Ceil(float64(ss.Len()) / math.Abs(r.ratio)))
+}
+
+// Position returns the current position in the resampled data. If the ratio is zero, Position
+// returns 0.
+func (r *Resampler) Position() int {
+	if r.ratio == 0 {
+		return 0
+	}
+	return int(math.Round((r.j0 + float64(r.pos-r.pos0)*r.ratio) / math.Abs(r.ratio)))
+}
+
+// Seek seeks to the position p in the resampled data. The original Streamer is seeked to the
+// corresponding position and the interpolation starts afresh from the original data there. If the
+// original Streamer is not a StreamSeeker, Seek returns an error.
+func (r *Resampler) Seek(p int) error {
+	ss, ok := r.s.(StreamSeeker)
+	if !ok {
+		return fmt.Errorf("resample: seek: original Streamer is not a StreamSeeker")
+	}
+	if p < 0 || r.Len() < p {
+		return fmt.Errorf("resample: seek position %v out of range [%v, %v]", p, 0, r.Len())
+	}
+	if err := r.load(ss, int(float64(p)*math.Abs(r.ratio))); err != nil {
+		return err
+	}
+	if r.ratio > 0 {
+		r.j0, r.pos0, r.pos = 0, 0, p
+	} else {
+		r.j0, r.pos0, r.pos = float64(p)*-r.ratio, p, p
+	}
+	return nil
+}
+
+// Ratio returns the resampling ratio set by the last call to SetRatio or GlideRatio, even if the
+// glide to it hasn't finished yet.
 func (r *Resampler) Ratio() float64 {
-	return r.ratio
+	return r.target
 }
 
-// SetRatio sets the resampling ratio. This does not cause any glitches in the stream.
+// SetRatio sets the resampling ratio. The ratio changes gradually over the next call to Stream,
+// which avoids zipper noise when the ratio changes often. This does not cause any glitches in the
+// stream.
 func (r *Resampler) SetRatio(ratio float64) {
-	r.pos = int(float64(r.pos) * r.ratio / ratio)
-	r.ratio = ratio
+	r.GlideRatio(ratio, -1)
+}
+
+// GlideRatio changes the resampling ratio linearly over the next n samples. If n is zero, the ratio
+// changes instantly. If n is negative, the ratio changes over the next call to Stream, just like
+// with SetRatio.
+func (r *Resampler) GlideRatio(ratio float64, n int) {
+	r.j0 += float64(r.pos-r.pos0) * r.ratio
+	r.pos0 = r.pos
+	r.target = ratio
+	r.glide = n
+	if n == 0 {
+		r.ratio = ratio
+	}
 }
 
 // lagrange calculates the value at x of a polynomial of order len(pts)+1 which goes through all
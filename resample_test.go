@@ -1,6 +1,7 @@
 package beep_test
 
 import (
+	"math"
 	"reflect"
 	"testing"
 
@@ -74,3 +75,182 @@ func lagrange(pts []point, x float64) (y float64) {
 type point struct {
 	X, Y float64
 }
+
+// sineStreamer returns a Streamer of numSamples samples of a sine wave with the frequency of
+// freq cycles per sample.
+func sineStreamer(numSamples int, freq float64) beep.Streamer {
+	i := 0
+	return beep.Take(numSamples, beep.StreamerFunc(func(samples [][2]float64) (n int, ok bool) {
+		for j := range samples {
+			x := math.Sin(2 * math.Pi * freq * float64(i))
+			samples[j] = [2]float64{x, x}
+			i++
+		}
+		return len(samples), true
+	}))
+}
+
+func TestResampleSinc(t *testing.T) {
+	for _, quality := range []beep.SincQuality{beep.SincFast, beep.SincMedium, beep.SincBest} {
+		for _, rates := range [][2]beep.SampleRate{{44100, 48000}, {48000, 44100}, {44100, 22050}} {
+			old, new := rates[0], rates[1]
+			freq := 1000.0
+
+			got := collect(beep.ResampleSinc(quality, old, new, sineStreamer(int(old), freq/float64(old))))
+			if want := int(new); len(got) < want-1 || len(got) > want+1 {
+				t.Fatalf("ResampleSinc streamed %d samples, expected %d", len(got), want)
+			}
+
+			// skip the edges, where the filter sees silence
+			for i := 1000; i < len(got)-1000; i++ {
+				want := math.Sin(2 * math.Pi * freq / float64(new) * float64(i))
+				if math.Abs(got[i][0]-want) > 1e-3 {
+					t.Fatalf("ResampleSinc sample %d too different: expected: %v, actual: %v (quality: %d, %v -> %v)", i, want, got[i][0], quality, old, new)
+				}
+			}
+		}
+	}
+}
+
+func TestResampleSincAntiAliasing(t *testing.T) {
+	// a tone above the new Nyquist frequency must be filtered out, not folded back
+	old, new := beep.SampleRate(48000), beep.SampleRate(16000)
+	got := collect(beep.ResampleSinc(beep.SincMedium, old, new, sineStreamer(int(old), 12000.0/float64(old))))
+
+	var energy float64
+	for i := 1000; i < len(got)-1000; i++ {
+		energy += got[i][0] * got[i][0]
+	}
+	if rms := math.Sqrt(energy / float64(len(got)-2000)); rms > 1e-2 {
+		t.Fatalf("ResampleSinc aliasing too loud: RMS %v", rms)
+	}
+}
+
+func TestResamplerSeek(t *testing.T) {
+	s, data := randomDataStreamer(10000)
+	want := resampleCorrect(3, 44100, 48000, data)
+
+	r := beep.Resample(3, 44100, 48000, s)
+	if !r.Seekable() {
+		t.Fatal("Resampler of a StreamSeeker not seekable")
+	}
+	if r.Len() != len(want) {
+		t.Fatalf("Resampler length: expected: %v, actual: %v", len(want), r.Len())
+	}
+	for _, p := range []int{5000, 0, 1234, len(want)} {
+		if err := r.Seek(p); err != nil {
+			t.Fatal(err)
+		}
+		if r.Position() != p {
+			t.Fatalf("Resampler position after seek: expected: %v, actual: %v", p, r.Position())
+		}
+		got := collect(r)
+		if len(got) != len(want)-p {
+			t.Fatalf("Resampler streamed %d samples after seeking to %d, expected %d", len(got), p, len(want)-p)
+		}
+		for i := range got {
+			if math.Abs(got[i][0]-want[p+i][0]) > 1e-9 || math.Abs(got[i][1]-want[p+i][1]) > 1e-9 {
+				t.Fatalf("Resampler not correct after seeking to %d", p)
+			}
+		}
+	}
+
+	r = beep.Resample(3, 44100, 48000, beep.Take(100, beep.Silence(-1)))
+	if r.Seekable() || r.Len() != 0 {
+		t.Fatalf("Resampler of a non-StreamSeeker seekable: %v, length: %v", r.Seekable(), r.Len())
+	}
+	if err := r.Seek(0); err == nil {
+		t.Fatal("Resampler seeking a non-StreamSeeker didn't fail")
+	}
+}
+
+func TestSincResamplerSeek(t *testing.T) {
+	s, _ := randomDataStreamer(10000)
+	r := beep.ResampleSinc(beep.SincFast, 44100, 48000, s)
+	want := collect(r)
+	if r.Len() != len(want) {
+		t.Fatalf("SincResampler length: expected: %v, actual: %v", len(want), r.Len())
+	}
+	for _, p := range []int{5000, 0, 1234} {
+		if err := r.Seek(p); err != nil {
+			t.Fatal(err)
+		}
+		if r.Position() != p {
+			t.Fatalf("SincResampler position after seek: expected: %v, actual: %v", p, r.Position())
+		}
+		got := collect(r)
+		if len(got) != len(want)-p {
+			t.Fatalf("SincResampler streamed %d samples after seeking to %d, expected %d", len(got), p, len(want)-p)
+		}
+		for i := range got {
+			if math.Abs(got[i][0]-want[p+i][0]) > 1e-9 || math.Abs(got[i][1]-want[p+i][1]) > 1e-9 {
+				t.Fatalf("SincResampler not correct after seeking to %d", p)
+			}
+		}
+	}
+	if !r.Seekable() {
+		t.Fatal("SincResampler of a StreamSeeker not seekable")
+	}
+
+	r = beep.ResampleSinc(beep.SincFast, 44100, 48000, beep.Take(100, beep.Silence(-1)))
+	if r.Seekable() || r.Len() != 0 {
+		t.Fatalf("SincResampler of a non-StreamSeeker seekable: %v, length: %v", r.Seekable(), r.Len())
+	}
+	if err := r.Seek(0); err == nil {
+		t.Fatal("SincResampler seeking a non-StreamSeeker didn't fail")
+	}
+}
+
+func TestResamplerBackwards(t *testing.T) {
+	s, data := randomDataStreamer(5000)
+	r := beep.ResampleRatio(3, -1, s)
+	if err := r.Seek(len(data) - 1); err != nil {
+		t.Fatal(err)
+	}
+	got := collect(r)
+	if len(got) != len(data) {
+		t.Fatalf("Resampler streamed %d samples backwards, expected %d", len(got), len(data))
+	}
+	for i := range got {
+		if got[i] != data[len(data)-1-i] {
+			t.Fatalf("Resampler backwards sample %d not correct", i)
+		}
+	}
+
+	s, _ = randomDataStreamer(5000)
+	sr := beep.ResampleSincRatio(beep.SincFast, 1, s)
+	want := collect(sr)
+	sr.GlideRatio(-1, 0)
+	if err := sr.Seek(len(want) - 1); err != nil {
+		t.Fatal(err)
+	}
+	got = collect(sr)
+	if len(got) != len(want) {
+		t.Fatalf("SincResampler streamed %d samples backwards, expected %d", len(got), len(want))
+	}
+	for i := range got {
+		w := want[len(want)-1-i]
+		if math.Abs(got[i][0]-w[0]) > 1e-9 || math.Abs(got[i][1]-w[1]) > 1e-9 {
+			t.Fatalf("SincResampler backwards sample %d not correct", i)
+		}
+	}
+}
+
+func TestResamplerGlide(t *testing.T) {
+	s, _ := randomDataStreamer(10000)
+	r := beep.ResampleRatio(3, 1, s)
+	buf := make([][2]float64, 100)
+	r.Stream(buf)
+
+	r.SetRatio(2)
+	if r.Ratio() != 2 {
+		t.Fatalf("Resampler ratio: expected: 2, actual: %v", r.Ratio())
+	}
+	r.Stream(buf)
+	// the ratio goes linearly from 1 to 2 over the buffer, so the original data advances by
+	// roughly 150 samples
+	r.GlideRatio(1, 0)
+	if p := r.Position(); p < 245 || p > 255 {
+		t.Fatalf("Resampler position after glide: expected: ~250, actual: %v", p)
+	}
+}
@@ -0,0 +1,95 @@
+package beep
+
+import "fmt"
+
+// reverseBlock is the number of samples Reverse reads from the original StreamSeeker at once.
+const reverseBlock = 4096
+
+// Reverse returns a StreamSeeker which streams s backwards, from its end to its beginning.
+// Positions are mirrored, position 0 of the returned StreamSeeker is the end of s.
+//
+// The original StreamSeeker is read in blocks, seeking only once per block, so Reverse works well
+// even with decoders which seek slowly. The position of s is changed by streaming, so s shouldn't
+// be used by anything else at the same time.
+//
+// The returned StreamSeeker propagates s's errors, including seeking errors during streaming.
+func Reverse(s StreamSeeker) StreamSeeker {
+	return &reverse{s: s}
+}
+
+type reverse struct {
+	s       StreamSeeker
+	pos     int
+	buf     [][2]float64 // block of samples of s starting at bufFrom
+	bufFrom int
+	err     error
+}
+
+func (r *reverse) Stream(samples [][2]float64) (n int, ok bool) {
+	if r.Err() != nil {
+		return 0, false
+	}
+	length := r.s.Len()
+	for len(samples) > 0 && r.pos < length {
+		// index of the sample right after the next one to stream in the original StreamSeeker
+		hi := length - r.pos
+		if hi <= r.bufFrom || r.bufFrom+len(r.buf) < hi {
+			if err := r.load(hi); err != nil {
+				r.err = err
+				break
+			}
+		}
+		k := hi - r.bufFrom
+		if k > len(samples) {
+			k = len(samples)
+		}
+		for i := range samples[:k] {
+			samples[i] = r.buf[hi-1-i-r.bufFrom]
+		}
+		samples = samples[k:]
+		r.pos += k
+		n += k
+	}
+	return n, n > 0
+}
+
+// load loads the block of samples of the original StreamSeeker which ends at hi.
+func (r *reverse) load(hi int) error {
+	from := hi - reverseBlock
+	if from < 0 {
+		from = 0
+	}
+	if err := r.s.Seek(from); err != nil {
+		return err
+	}
+	if cap(r.buf) < reverseBlock {
+		r.buf = make([][2]float64, reverseBlock)
+	}
+	r.buf = r.buf[:hi-from]
+	r.bufFrom = from
+	streamFull(r.s, r.buf)
+	return r.s.Err()
+}
+
+func (r *reverse) Err() error {
+	if r.err != nil {
+		return r.err
+	}
+	return r.s.Err()
+}
+
+func (r *reverse) Len() int {
+	return r.s.Len()
+}
+
+func (r *reverse) Position() int {
+	return r.pos
+}
+
+func (r *reverse) Seek(p int) error {
+	if p < 0 || r.s.Len() < p {
+		return fmt.Errorf("reverse: seek position %v out of range [%v, %v]", p, 0, r.s.Len())
+	}
+	r.pos = p
+	return nil
+}
@@ -0,0 +1,157 @@
+package beep
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// NewRingBuffer returns a RingBuffer which can hold size samples.
+//
+// RingBuffer connects a producer of audio running on its own goroutine, such as a network decoder
+// or a game thread, with the speaker. The producer writes samples to it, the speaker streams them,
+// and neither of them needs to lock the speaker.
+//
+//   rb := beep.NewRingBuffer(sr.N(time.Second / 5))
+//   speaker.Play(rb)
+//   go func() {
+//       for {
+//           samples := generate()
+//           if _, err := rb.Write(samples); err != nil {
+//               return
+//           }
+//       }
+//   }()
+//
+// NewRingBuffer panics if size is not positive.
+func NewRingBuffer(size int) *RingBuffer {
+	if size <= 0 {
+		panic(fmt.Errorf("ring buffer: invalid size: %d", size))
+	}
+	return &RingBuffer{
+		buf:      make([][2]float64, size),
+		readable: make(chan struct{}, 1),
+	}
+}
+
+// RingBuffer is a single-producer, single-consumer queue of samples created by NewRingBuffer.
+//
+// The writing side (Write, TryWrite and Close) must only be used by one goroutine at a time and
+// the reading side (Stream) must only be used by one goroutine at a time, but the two sides can
+// run concurrently without any locking.
+//
+// When the producer falls behind, Stream fills the missing samples with silence and counts an
+// underrun. After Close, Stream streams the remaining samples and then drains.
+type RingBuffer struct {
+	// the counters are accessed atomically, so they're first to keep them 64-bit aligned
+	read      uint64 // total number of samples read
+	write     uint64 // total number of samples written
+	underruns uint64
+	closed    uint32
+
+	buf      [][2]float64
+	readable chan struct{} // notifies the writer that Stream made some space
+}
+
+// Len returns the number of samples the RingBuffer can hold.
+func (rb *RingBuffer) Len() int {
+	return len(rb.buf)
+}
+
+// Buffered returns the number of samples written, but not yet streamed.
+func (rb *RingBuffer) Buffered() int {
+	return int(atomic.LoadUint64(&rb.write) - atomic.LoadUint64(&rb.read))
+}
+
+// TryWrite writes as many samples as fit into the RingBuffer without waiting and returns their
+// number. TryWrite returns 0 after the RingBuffer was closed.
+func (rb *RingBuffer) TryWrite(samples [][2]float64) int {
+	if atomic.LoadUint32(&rb.closed) != 0 {
+		return 0
+	}
+	write := atomic.LoadUint64(&rb.write)
+	free := len(rb.buf) - int(write-atomic.LoadUint64(&rb.read))
+	if free < len(samples) {
+		samples = samples[:free]
+	}
+	i := int(write % uint64(len(rb.buf)))
+	n := copy(rb.buf[i:], samples)
+	copy(rb.buf, samples[n:])
+	atomic.StoreUint64(&rb.write, write+uint64(len(samples)))
+	return len(samples)
+}
+
+// Write writes all the samples into the RingBuffer, waiting for Stream to make space for them if
+// necessary. Write returns an error if the RingBuffer gets closed before all the samples are
+// written.
+func (rb *RingBuffer) Write(samples [][2]float64) (n int, err error) {
+	for {
+		wn := rb.TryWrite(samples[n:])
+		n += wn
+		if n == len(samples) {
+			return n, nil
+		}
+		if atomic.LoadUint32(&rb.closed) != 0 {
+			return n, fmt.Errorf("ring buffer: write to closed ring buffer")
+		}
+		if wn == 0 {
+			<-rb.readable
+		}
+	}
+}
+
+// Close marks the end of the written data. Stream drains once the remaining samples got streamed.
+// Write and TryWrite don't write anything after Close.
+func (rb *RingBuffer) Close() error {
+	atomic.StoreUint32(&rb.closed, 1)
+	// wake up a waiting Write
+	select {
+	case rb.readable <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Stream streams the written samples. If there are not enough samples, Stream fills the rest with
+// silence and counts an underrun, unless the RingBuffer is closed, in which case it streams only
+// the remaining samples.
+func (rb *RingBuffer) Stream(samples [][2]float64) (n int, ok bool) {
+	// load closed first, so that no samples written before Close get lost
+	closed := atomic.LoadUint32(&rb.closed) != 0
+	read := atomic.LoadUint64(&rb.read)
+	n = int(atomic.LoadUint64(&rb.write) - read)
+	if n > len(samples) {
+		n = len(samples)
+	}
+	i := int(read % uint64(len(rb.buf)))
+	cn := copy(samples[:n], rb.buf[i:])
+	copy(samples[cn:n], rb.buf)
+	atomic.StoreUint64(&rb.read, read+uint64(n))
+	if n > 0 {
+		select {
+		case rb.readable <- struct{}{}:
+		default:
+		}
+	}
+
+	if closed {
+		return n, n > 0
+	}
+	if n < len(samples) {
+		atomic.AddUint64(&rb.underruns, 1)
+		for i := range samples[n:] {
+			samples[n+i] = [2]float64{}
+		}
+	}
+	return len(samples), true
+}
+
+// Err always returns nil.
+func (rb *RingBuffer) Err() error {
+	return nil
+}
+
+// Underruns returns the number of calls to Stream which ran out of the written samples and had
+// to stream silence instead.
+func (rb *RingBuffer) Underruns() int {
+	return int(atomic.LoadUint64(&rb.underruns))
+}
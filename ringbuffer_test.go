@@ -0,0 +1,77 @@
+package beep_test
+
+import (
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/faiface/beep"
+)
+
+func TestRingBuffer(t *testing.T) {
+	_, data := randomDataStreamer(54321)
+	rb := beep.NewRingBuffer(1000)
+
+	go func() {
+		for i := 0; i < len(data); i += 777 {
+			j := i + 777
+			if j > len(data) {
+				j = len(data)
+			}
+			if _, err := rb.Write(data[i:j]); err != nil {
+				panic(err)
+			}
+		}
+		rb.Close()
+	}()
+
+	var got [][2]float64
+	buf := make([][2]float64, 512)
+	for {
+		if rb.Buffered() == 0 {
+			runtime.Gosched()
+		}
+		n, ok := rb.Stream(buf)
+		if !ok {
+			break
+		}
+		// skip the silence streamed on underruns
+		for _, s := range buf[:n] {
+			if s != [2]float64{} {
+				got = append(got, s)
+			}
+		}
+	}
+	if !reflect.DeepEqual(data, got) {
+		t.Fatal("RingBuffer not working correctly")
+	}
+}
+
+func TestRingBufferUnderrun(t *testing.T) {
+	rb := beep.NewRingBuffer(100)
+	if n := rb.TryWrite(make([][2]float64, 150)); n != 100 {
+		t.Fatalf("TryWrite wrote %d samples, expected 100", n)
+	}
+
+	buf := make([][2]float64, 60)
+	for i := 0; i < 2; i++ {
+		if n, ok := rb.Stream(buf); n != 60 || !ok {
+			t.Fatalf("Stream returned %d, %v", n, ok)
+		}
+	}
+	if rb.Underruns() != 1 || rb.Buffered() != 0 {
+		t.Fatalf("expected 1 underrun and nothing buffered, got %d and %d", rb.Underruns(), rb.Buffered())
+	}
+
+	rb.TryWrite(make([][2]float64, 10))
+	rb.Close()
+	if n, ok := rb.Stream(buf); n != 10 || !ok {
+		t.Fatalf("Stream after Close returned %d, %v", n, ok)
+	}
+	if _, ok := rb.Stream(buf); ok {
+		t.Fatal("RingBuffer not drained after Close")
+	}
+	if _, err := rb.Write(buf); err == nil {
+		t.Fatal("Write to a closed RingBuffer succeeded")
+	}
+}
@@ -0,0 +1,351 @@
+package beep
+
+import (
+	"fmt"
+	"math"
+	"sync"
+)
+
+// SincQuality selects the quality of the band-limited interpolation done by SincResampler.
+// Higher quality implies longer filters, a sharper low-pass and worse performance.
+type SincQuality int
+
+// Quality presets for ResampleSinc and ResampleSincRatio.
+//
+//   quality    | use case
+//   -----------|---------
+//   SincFast   | on-the-fly resampling, good quality
+//   SincMedium | on-the-fly resampling with higher CPU usage, very good quality
+//   SincBest   | offline resampling, transparent quality
+const (
+	SincFast SincQuality = iota
+	SincMedium
+	SincBest
+)
+
+// sincParams are the filter parameters for each SincQuality. The filter spans zeroCrossings zero
+// crossings of the sinc function on each side, the table stores resolution points per zero
+// crossing, beta is the parameter of the Kaiser window and rolloff is the cutoff frequency
+// relative to the Nyquist frequency.
+var sincParams = [...]struct {
+	zeroCrossings int
+	resolution    int
+	beta          float64
+	rolloff       float64
+}{
+	SincFast:   {8, 128, 6, 0.85},
+	SincMedium: {16, 256, 8.6, 0.92},
+	SincBest:   {32, 512, 10, 0.96},
+}
+
+var (
+	sincTablesOnce [len(sincParams)]sync.Once
+	sincTables     [len(sincParams)]*sincTable
+)
+
+// sincTable is a precomputed right half of a Kaiser windowed sinc filter.
+type sincTable struct {
+	zeroCrossings int
+	resolution    float64
+	rolloff       float64
+	h             []float64 // h[i] is the filter value at i/resolution zero crossings
+	dh            []float64 // dh[i] = h[i+1] - h[i], for linear interpolation
+}
+
+func getSincTable(q SincQuality) *sincTable {
+	sincTablesOnce[q].Do(func() {
+		p := sincParams[q]
+		n := p.zeroCrossings * p.resolution
+		t := &sincTable{
+			zeroCrossings: p.zeroCrossings,
+			resolution:    float64(p.resolution),
+			rolloff:       p.rolloff,
+			h:             make([]float64, n+2),
+			dh:            make([]float64, n+1),
+		}
+		for i := 0; i <= n; i++ {
+			x := float64(i) / float64(p.resolution)
+			w := float64(i) / float64(n)
+			t.h[i] = sinc(x) * besselI0(p.beta*math.Sqrt(1-w*w)) / besselI0(p.beta)
+		}
+		for i := 0; i <= n; i++ {
+			t.dh[i] = t.h[i+1] - t.h[i]
+		}
+		sincTables[q] = t
+	})
+	return sincTables[q]
+}
+
+// at returns the filter value at x zero crossings from the center. x must be non-negative.
+func (t *sincTable) at(x float64) float64 {
+	x *= t.resolution
+	i := int(x)
+	if i >= len(t.dh) {
+		return 0
+	}
+	return t.h[i] + (x-float64(i))*t.dh[i]
+}
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
+
+// besselI0 calculates the modified Bessel function of the first kind of order zero.
+func besselI0(x float64) float64 {
+	sum, term := 1.0, 1.0
+	for k := 1; term > sum*1e-16; k++ {
+		term *= (x / 2 / float64(k)) * (x / 2 / float64(k))
+		sum += term
+	}
+	return sum
+}
+
+// ResampleSinc is like Resample, but uses band-limited interpolation with a windowed sinc filter
+// instead of polynomial interpolation. When downsampling, the filter also removes frequencies above
+// the new Nyquist frequency, so there's no aliasing. The cost per sample depends only on the
+// quality preset (and grows with the ratio when downsampling), not quadratically on the quality.
+//
+//   sr := beep.SampleRate(48000)
+//   speaker.Init(sr, sr.N(time.Second/10))
+//   speaker.Play(beep.ResampleSinc(beep.SincFast, format.SampleRate, sr, s))
+//
+// ResampleSinc panics if the quality is not one of the presets.
+//
+// The returned SincResampler has the methods of a StreamSeeker even if s is not one, but then it
+// can't seek and its length is 0, see Seekable.
+//
+// ResampleSinc propagates errors from s.
+func ResampleSinc(quality SincQuality, old, new SampleRate, s Streamer) *SincResampler {
+	return ResampleSincRatio(quality, float64(old)/float64(new), s)
+}
+
+// ResampleSincRatio is same as ResampleSinc, except it takes the ratio of the old and the new
+// sample rate, just like ResampleRatio.
+func ResampleSincRatio(quality SincQuality, ratio float64, s Streamer) *SincResampler {
+	if quality < 0 || int(quality) >= len(sincParams) {
+		panic(fmt.Errorf("resample: invalid sinc quality: %d", quality))
+	}
+	return &SincResampler{
+		s:      s,
+		table:  getSincTable(quality),
+		ratio:  ratio,
+		target: ratio,
+		end:    -1,
+	}
+}
+
+// SincResampler is a Streamer created by ResampleSinc and ResampleSincRatio functions. Same as
+// Resampler, it allows dynamic changing of the resampling ratio (including negative ratios for
+// StreamSeekers) and it can be used as a StreamSeeker if the original Streamer is a StreamSeeker.
+// It always implements the StreamSeeker interface though, use Seekable to check whether it can
+// seek.
+type SincResampler struct {
+	s      Streamer
+	table  *sincTable
+	ratio  float64
+	target float64      // target is the ratio at the end of the current glide
+	glide  int          // glide is the number of samples until ratio reaches target, -1 means the next buffer
+	buf    [][2]float64 // window of the original data
+	off    int          // off is the position of buf[0] in the original data
+	end    int          // end is the length of the original data, or -1 if it's not drained yet
+	tmp    [512][2]float64
+	t0     float64 // t0 is the position in the original data at pos0
+	pos0   int
+	pos    int // pos is the current position in the resampled data
+}
+
+// Stream streams the original audio resampled according to the current ratio.
+func (r *SincResampler) Stream(samples [][2]float64) (n int, ok bool) {
+	if r.glide < 0 {
+		r.glide = len(samples)
+	}
+	for i := range samples {
+		// t is the current position in the original data
+		t := r.t0 + float64(r.pos-r.pos0)*r.ratio
+		if t < 0 {
+			// we got to the beginning while playing backwards
+			break
+		}
+		scale, half := r.scale(), r.half()
+		lo, hi := int(math.Ceil(t-half)), int(math.Floor(t+half))
+		if lo < r.off && r.off > 0 {
+			// playing backwards, we need to load older data
+			if r.reload(lo) != nil {
+				break
+			}
+		}
+		r.fill(hi)
+		if r.end >= 0 && t >= float64(r.end) {
+			break
+		}
+		if r.ratio > 0 {
+			r.drop(lo)
+		}
+
+		var y [2]float64
+		from, to := lo, hi
+		if from < r.off {
+			from = r.off
+		}
+		if to >= r.off+len(r.buf) {
+			to = r.off + len(r.buf) - 1
+		}
+		for k := from; k <= to; k++ {
+			h := r.table.at(math.Abs(t-float64(k)) * scale)
+			x := r.buf[k-r.off]
+			y[0] += x[0] * h
+			y[1] += x[1] * h
+		}
+		samples[i] = [2]float64{y[0] * scale, y[1] * scale}
+		r.pos++
+		n++
+
+		// move the ratio towards the target, keeping the position in the original data continuous
+		if r.glide > 0 {
+			r.t0, r.pos0 = t+r.ratio, r.pos
+			r.ratio += (r.target - r.ratio) / float64(r.glide)
+			r.glide--
+		}
+	}
+	return n, n > 0
+}
+
+// scale returns the cutoff of the filter relative to the Nyquist frequency of the original data.
+func (r *SincResampler) scale() float64 {
+	if ratio := math.Abs(r.ratio); ratio > 1 {
+		return r.table.rolloff / ratio
+	}
+	return r.table.rolloff
+}
+
+// half returns the half-width of the filter in samples of the original data.
+func (r *SincResampler) half() float64 {
+	return float64(r.table.zeroCrossings) / r.scale()
+}
+
+// fill reads the original data until buf contains the sample at position hi, or the original
+// Streamer is drained.
+func (r *SincResampler) fill(hi int) {
+	for r.end < 0 && r.off+len(r.buf) <= hi {
+		sn, sok := r.s.Stream(r.tmp[:])
+		r.buf = append(r.buf, r.tmp[:sn]...)
+		if !sok {
+			r.end = r.off + len(r.buf)
+		}
+	}
+}
+
+// reload seeks the original data to a few blocks before position lo and starts filling buf from
+// there. This is used when playing backwards.
+func (r *SincResampler) reload(lo int) error {
+	ss, ok := r.s.(StreamSeeker)
+	if !ok {
+		return fmt.Errorf("resample: original Streamer is not a StreamSeeker")
+	}
+	from := lo - 4*len(r.tmp)
+	if from < 0 {
+		from = 0
+	}
+	if err := ss.Seek(from); err != nil {
+		return err
+	}
+	r.buf = r.buf[:0]
+	r.off = from
+	r.end = -1
+	return nil
+}
+
+// drop removes the samples before position lo from buf.
+func (r *SincResampler) drop(lo int) {
+	if k := lo - r.off; k > len(r.tmp) && k <= len(r.buf) {
+		r.buf = r.buf[:copy(r.buf, r.buf[k:])]
+		r.off = lo
+	}
+}
+
+// Err propagates the original Streamer's errors.
+func (r *SincResampler) Err() error {
+	return r.s.Err()
+}
+
+// Seekable reports whether the original Streamer is a StreamSeeker, that is, whether the
+// SincResampler can seek and knows its length.
+func (r *SincResampler) Seekable() bool {
+	_, ok := r.s.(StreamSeeker)
+	return ok
+}
+
+// Len returns the total number of samples of the resampled data. If the original Streamer is not
+// a StreamSeeker or the ratio is zero, Len returns 0.
+func (r *SincResampler) Len() int {
+	ss, ok := r.s.(StreamSeeker)
+	if !ok || r.ratio == 0 {
+		return 0
+	}
+	return int(math.Ceil(float64(ss.Len()) / math.Abs(r.ratio)))
+}
+
+// Position returns the current position in the resampled data. If the ratio is zero, Position
+// returns 0.
+func (r *SincResampler) Position() int {
+	if r.ratio == 0 {
+		return 0
+	}
+	return int(math.Round((r.t0 + float64(r.pos-r.pos0)*r.ratio) / math.Abs(r.ratio)))
+}
+
+// Seek seeks to the position p in the resampled data. The original Streamer is seeked to the
+// corresponding position, so that the filter sees the original data around it. If the original
+// Streamer is not a StreamSeeker, Seek returns an error.
+func (r *SincResampler) Seek(p int) error {
+	ss, ok := r.s.(StreamSeeker)
+	if !ok {
+		return fmt.Errorf("resample: seek: original Streamer is not a StreamSeeker")
+	}
+	if p < 0 || r.Len() < p {
+		return fmt.Errorf("resample: seek position %v out of range [%v, %v]", p, 0, r.Len())
+	}
+
+	t := float64(p) * math.Abs(r.ratio)
+	off := int(math.Floor(t - r.half()))
+	if off < 0 {
+		off = 0
+	}
+	if err := ss.Seek(off); err != nil {
+		return err
+	}
+	r.buf = r.buf[:0]
+	r.off = off
+	r.end = -1
+	r.t0, r.pos0, r.pos = t, p, p
+	return nil
+}
+
+// Ratio returns the resampling ratio set by the last call to SetRatio or GlideRatio, even if the
+// glide to it hasn't finished yet.
+func (r *SincResampler) Ratio() float64 {
+	return r.target
+}
+
+// SetRatio sets the resampling ratio. The ratio changes gradually over the next call to Stream,
+// which avoids zipper noise when the ratio changes often. This does not cause any glitches in the
+// stream.
+func (r *SincResampler) SetRatio(ratio float64) {
+	r.GlideRatio(ratio, -1)
+}
+
+// GlideRatio changes the resampling ratio linearly over the next n samples. If n is zero, the ratio
+// changes instantly. If n is negative, the ratio changes over the next call to Stream, just like
+// with SetRatio.
+func (r *SincResampler) GlideRatio(ratio float64, n int) {
+	r.t0 += float64(r.pos-r.pos0) * r.ratio
+	r.pos0 = r.pos
+	r.target = ratio
+	r.glide = n
+	if n == 0 {
+		r.ratio = ratio
+	}
+}
@@ -88,11 +88,13 @@ func Unlock() {
 	mu.Unlock()
 }
 
-// Play starts playing all provided Streamers through the speaker.
-func Play(s ...beep.Streamer) {
+// Play starts playing all provided Streamers through the speaker. The returned Track controls the
+// playing Streamers and can be used from any goroutine without locking the speaker.
+func Play(s ...beep.Streamer) *beep.Track {
 	mu.Lock()
-	mixer.Add(s...)
+	t := mixer.Add(s...)
 	mu.Unlock()
+	return t
 }
 
 // Clear removes all currently playing Streamers from the speaker.
@@ -1,25 +1,61 @@
 package beep
 
+import "fmt"
+
 // Silence returns a Streamer which streams num samples of silence. If num is negative, silence is
 // streamed forever.
+//
+// If num is not negative, the returned Streamer is a StreamSeeker.
 func Silence(num int) Streamer {
+	if num >= 0 {
+		return &silence{num: num}
+	}
 	return StreamerFunc(func(samples [][2]float64) (n int, ok bool) {
-		if num == 0 {
-			return 0, false
-		}
-		if 0 < num && num < len(samples) {
-			samples = samples[:num]
-		}
 		for i := range samples {
 			samples[i] = [2]float64{}
 		}
-		if num > 0 {
-			num -= len(samples)
-		}
 		return len(samples), true
 	})
 }
 
+type silence struct {
+	num, pos int
+}
+
+func (s *silence) Stream(samples [][2]float64) (n int, ok bool) {
+	if s.pos >= s.num {
+		return 0, false
+	}
+	if len(samples) > s.num-s.pos {
+		samples = samples[:s.num-s.pos]
+	}
+	for i := range samples {
+		samples[i] = [2]float64{}
+	}
+	s.pos += len(samples)
+	return len(samples), true
+}
+
+func (s *silence) Err() error {
+	return nil
+}
+
+func (s *silence) Len() int {
+	return s.num
+}
+
+func (s *silence) Position() int {
+	return s.pos
+}
+
+func (s *silence) Seek(p int) error {
+	if p < 0 || s.num < p {
+		return fmt.Errorf("silence: seek position %v out of range [%v, %v]", p, 0, s.num)
+	}
+	s.pos = p
+	return nil
+}
+
 // Callback returns a Streamer, which does not stream any samples, but instead calls f the first
 // time its Stream method is called. The speaker is locked while f is called.
 func Callback(f func()) Streamer {
@@ -0,0 +1,206 @@
+package beep
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// TeePolicy decides what happens when a consumer of a Tee falls behind the fastest one by more
+// than the maximum lag.
+type TeePolicy int
+
+// Policies for Tee.
+//
+//   policy   | when a consumer falls behind
+//   ---------|-----------------------------
+//   TeeBlock | the faster consumers wait until it catches up
+//   TeeDrop  | it skips the samples it missed
+//   TeeError | it drains with ErrTeeLag
+const (
+	TeeBlock TeePolicy = iota
+	TeeDrop
+	TeeError
+)
+
+// ErrTeeLag is the error of a Tee consumer which fell behind with the TeeError policy.
+var ErrTeeLag = errors.New("tee: consumer fell behind")
+
+// Tee returns n Streamers which all stream the same data as the original s. Unlike Dup, the
+// returned Streamers can be used concurrently from different goroutines, and the memory used for
+// buffering is bounded: no consumer can get more than maxLag samples ahead of the slowest one.
+// The policy decides what happens when it would.
+//
+// With TeeBlock, the Stream method of a consumer which got too far ahead waits for the slowest
+// one. The consumers must therefore be streamed from different goroutines, for example one played
+// by the speaker and the others read by analysis goroutines, otherwise TeeBlock deadlocks.
+//
+// A consumer which is no longer needed must be closed, so that it doesn't hold back the others.
+//
+// Tee panics if n or maxLag is not positive.
+//
+// The returned Streamers propagate s's errors once they stream all the samples before the error.
+func Tee(n, maxLag int, policy TeePolicy, s Streamer) []StreamCloser {
+	if n <= 0 {
+		panic(fmt.Errorf("tee: invalid number of consumers: %d", n))
+	}
+	if maxLag <= 0 {
+		panic(fmt.Errorf("tee: invalid maximum lag: %d", maxLag))
+	}
+	t := &tee{
+		s:      s,
+		policy: policy,
+		buf:    make([][2]float64, maxLag),
+	}
+	t.cond = sync.NewCond(&t.mu)
+	consumers := make([]StreamCloser, n)
+	for i := range consumers {
+		c := &teeConsumer{t: t}
+		t.consumers = append(t.consumers, c)
+		consumers[i] = c
+	}
+	return consumers
+}
+
+type tee struct {
+	mu        sync.Mutex
+	cond      *sync.Cond // signaled when a consumer advances or leaves
+	s         Streamer
+	policy    TeePolicy
+	buf       [][2]float64 // ring buffer, the sample at position p is at buf[p%len(buf)]
+	head      int          // number of samples streamed from s
+	drained   bool
+	err       error
+	consumers []*teeConsumer // consumers which are not closed and didn't fall behind
+}
+
+// slowest returns the position of the slowest consumer.
+func (t *tee) slowest() int {
+	pos := t.head
+	for _, c := range t.consumers {
+		if c.pos < pos {
+			pos = c.pos
+		}
+	}
+	return pos
+}
+
+// remove removes the consumer c, so that it doesn't hold back the others.
+func (t *tee) remove(c *teeConsumer) {
+	for i := range t.consumers {
+		if t.consumers[i] == c {
+			t.consumers = append(t.consumers[:i], t.consumers[i+1:]...)
+			break
+		}
+	}
+	t.cond.Broadcast()
+}
+
+// overtake applies the policy to the consumers which fell more than maxLag samples behind.
+func (t *tee) overtake() {
+	oldest := t.head - len(t.buf)
+	for i := 0; i < len(t.consumers); i++ {
+		c := t.consumers[i]
+		if c.pos >= oldest {
+			continue
+		}
+		switch t.policy {
+		case TeeDrop:
+			c.pos = oldest
+		case TeeError:
+			c.err = ErrTeeLag
+			t.remove(c)
+			i--
+		}
+	}
+}
+
+type teeConsumer struct {
+	t      *tee
+	pos    int
+	err    error
+	closed bool
+}
+
+func (c *teeConsumer) Stream(samples [][2]float64) (n int, ok bool) {
+	t := c.t
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for len(samples) > 0 && !c.closed && c.err == nil {
+		// the samples are buffered already
+		if c.pos < t.head {
+			k := t.head - c.pos
+			if k > len(samples) {
+				k = len(samples)
+			}
+			for i := range samples[:k] {
+				samples[i] = t.buf[(c.pos+i)%len(t.buf)]
+			}
+			c.pos += k
+			n += k
+			samples = samples[k:]
+			t.cond.Broadcast()
+			continue
+		}
+
+		if t.drained {
+			break
+		}
+
+		// this consumer is the fastest one, stream new samples from s
+		k := len(samples)
+		if k > len(t.buf) {
+			k = len(t.buf)
+		}
+		if t.policy == TeeBlock {
+			free := len(t.buf) - (t.head - t.slowest())
+			if free == 0 {
+				t.cond.Wait()
+				continue
+			}
+			if k > free {
+				k = free
+			}
+		}
+		sn, sok := t.s.Stream(samples[:k])
+		for i := range samples[:sn] {
+			t.buf[(t.head+i)%len(t.buf)] = samples[i]
+		}
+		t.head += sn
+		c.pos += sn
+		n += sn
+		samples = samples[sn:]
+		if !sok {
+			t.drained = true
+			t.err = t.s.Err()
+		}
+		t.overtake()
+		t.cond.Broadcast()
+	}
+	return n, n > 0
+}
+
+func (c *teeConsumer) Err() error {
+	t := c.t
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	if c.pos < t.head {
+		return nil
+	}
+	return t.err
+}
+
+func (c *teeConsumer) Close() error {
+	t := c.t
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !c.closed {
+		c.closed = true
+		t.remove(c)
+	}
+	return nil
+}
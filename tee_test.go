@@ -0,0 +1,61 @@
+package beep_test
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/faiface/beep"
+)
+
+func TestTeeBlock(t *testing.T) {
+	s, data := randomDataStreamer(54321)
+	consumers := beep.Tee(3, 1000, beep.TeeBlock, s)
+
+	got := make([][][2]float64, len(consumers))
+	var wg sync.WaitGroup
+	for i := range consumers {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			got[i] = collect(consumers[i])
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range got {
+		if !reflect.DeepEqual(data, got[i]) {
+			t.Fatalf("Tee consumer %d not streaming correctly", i)
+		}
+	}
+}
+
+func TestTeeDrop(t *testing.T) {
+	s, data := randomDataStreamer(5000)
+	consumers := beep.Tee(2, 1000, beep.TeeDrop, s)
+
+	if got := collect(consumers[0]); !reflect.DeepEqual(data, got) {
+		t.Fatal("Tee fastest consumer not streaming correctly")
+	}
+	if got := collect(consumers[1]); !reflect.DeepEqual(data[len(data)-1000:], got) {
+		t.Fatal("Tee slow consumer didn't drop the samples it missed")
+	}
+}
+
+func TestTeeError(t *testing.T) {
+	s, data := randomDataStreamer(5000)
+	consumers := beep.Tee(3, 1000, beep.TeeError, s)
+	consumers[2].Close()
+
+	buf := make([][2]float64, 500)
+	consumers[1].Stream(buf)
+	if got := collect(consumers[0]); !reflect.DeepEqual(data, got) {
+		t.Fatal("Tee fastest consumer not streaming correctly")
+	}
+	if n, ok := consumers[1].Stream(buf); n != 0 || ok {
+		t.Fatal("Tee slow consumer not drained")
+	}
+	if consumers[1].Err() != beep.ErrTeeLag {
+		t.Fatalf("expected ErrTeeLag, got %v", consumers[1].Err())
+	}
+}
@@ -0,0 +1,102 @@
+package beep
+
+import "sort"
+
+// Timeline mixes Streamers started at exact sample positions. Unlike playing Streamers through a
+// speaker or adding them to a Mixer, which starts them at the next buffer boundary, Timeline
+// starts each Streamer at exactly the scheduled sample, even in the middle of a buffer.
+//
+// Positions are counted in samples from the first sample streamed by the Timeline. Use
+// SampleRate.N to schedule Streamers at a time.
+//
+//   var tl beep.Timeline
+//   speaker.Play(&tl)
+//   // ...
+//   speaker.Lock()
+//   for i := 0; i < 4; i++ {
+//       tl.Schedule(tl.Position()+sr.N(time.Second/2)*i, kick.Streamer(0, kick.Len()))
+//   }
+//   speaker.Unlock()
+//
+// Same as Mixer, Timeline never drains, it streams silence when there's nothing to play. If the
+// Timeline is playing through the speaker, you need to lock the speaker when scheduling.
+type Timeline struct {
+	mixer   Mixer
+	pos     int
+	pending []scheduled // sorted by the starting position
+}
+
+type scheduled struct {
+	at int
+	t  *Track
+}
+
+// Schedule schedules the Streamers to start playing at the given position and returns a Track
+// controlling them. If the position already passed, the Streamers start right away. The Track can
+// be removed or paused even before it starts playing.
+func (tl *Timeline) Schedule(at int, s ...Streamer) *Track {
+	t := newTrack(s...)
+	i := sort.Search(len(tl.pending), func(i int) bool {
+		return tl.pending[i].at > at
+	})
+	tl.pending = append(tl.pending, scheduled{})
+	copy(tl.pending[i+1:], tl.pending[i:])
+	tl.pending[i] = scheduled{at, t}
+	return t
+}
+
+// Position returns the position of the next sample streamed by the Timeline.
+func (tl *Timeline) Position() int {
+	return tl.pos
+}
+
+// Pending returns the number of scheduled Streamers which haven't started playing yet.
+func (tl *Timeline) Pending() int {
+	n := 0
+	for _, sc := range tl.pending {
+		n += len(sc.t.s)
+	}
+	return n
+}
+
+// Playing returns the number of Streamers currently playing in the Timeline.
+func (tl *Timeline) Playing() int {
+	return tl.mixer.Len()
+}
+
+// Clear removes all scheduled and playing Streamers from the Timeline. The position is unchanged.
+func (tl *Timeline) Clear() {
+	for _, sc := range tl.pending {
+		sc.t.Remove()
+	}
+	tl.pending = tl.pending[:0]
+	tl.mixer.Clear()
+}
+
+// Stream streams all the playing Streamers mixed together, starting the scheduled Streamers at
+// their exact positions. This method always returns len(samples), true.
+func (tl *Timeline) Stream(samples [][2]float64) (n int, ok bool) {
+	for len(samples) > 0 {
+		// start everything that's due
+		for len(tl.pending) > 0 && tl.pending[0].at <= tl.pos {
+			tl.mixer.add(tl.pending[0].t)
+			tl.pending = tl.pending[1:]
+		}
+
+		// stream until the next scheduled start
+		toStream := len(samples)
+		if len(tl.pending) > 0 && tl.pending[0].at-tl.pos < toStream {
+			toStream = tl.pending[0].at - tl.pos
+		}
+		tl.mixer.Stream(samples[:toStream])
+		samples = samples[toStream:]
+		tl.pos += toStream
+		n += toStream
+	}
+	return n, true
+}
+
+// Err always returns nil for Timeline. See Mixer.Err for the reasons.
+func (tl *Timeline) Err() error {
+	return nil
+}
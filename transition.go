@@ -0,0 +1,202 @@
+package beep
+
+import (
+	"fmt"
+	"math"
+)
+
+// Curve describes the shape of a fade. It maps the progress of a fade-in, going from 0 to 1, to the
+// gain of the faded-in Streamer. The faded-out Streamer uses the gain of curve(1-x). A Curve
+// should return 0 for 0 and 1 for 1.
+type Curve func(x float64) float64
+
+// LinearCurve changes the gain linearly. Crossfading correlated material (such as two parts of the
+// same recording) with LinearCurve keeps a constant level.
+func LinearCurve(x float64) float64 {
+	return x
+}
+
+// EqualPowerCurve keeps the sum of squares of the two gains constant. Crossfading uncorrelated
+// material (such as two different songs) with EqualPowerCurve keeps a constant perceived level.
+func EqualPowerCurve(x float64) float64 {
+	return math.Sin(x * math.Pi / 2)
+}
+
+// ExponentialCurve changes the gain exponentially over the range of 60dB, which makes the fade
+// sound linear to the human ear.
+func ExponentialCurve(x float64) float64 {
+	return (math.Pow(1000, x) - 1) / 999
+}
+
+// Transition returns a Streamer which fades out a while fading in b over the duration of num
+// samples, following the provided curve. Afterwards, it streams just b. The transition starts
+// right at the beginning of streaming.
+//
+// Transition panics if num is negative.
+//
+// Transition does not propagate errors from the Streamers.
+func Transition(curve Curve, num int, a, b Streamer) Streamer {
+	if num < 0 {
+		panic(fmt.Errorf("transition: invalid number of samples: %v", num))
+	}
+	return &transition{
+		curve: curve,
+		num:   num,
+		a:     a,
+		b:     b,
+	}
+}
+
+type transition struct {
+	curve Curve
+	num   int
+	pos   int
+	a, b  Streamer
+	tmp   [512][2]float64
+}
+
+func (t *transition) Stream(samples [][2]float64) (n int, ok bool) {
+	for t.pos < t.num && len(samples) > 0 {
+		toStream := t.num - t.pos
+		if toStream > len(samples) {
+			toStream = len(samples)
+		}
+		if toStream > len(t.tmp) {
+			toStream = len(t.tmp)
+		}
+
+		an, _ := t.a.Stream(samples[:toStream])
+		for i := range samples[an:toStream] {
+			samples[an+i] = [2]float64{}
+		}
+		bn, _ := t.b.Stream(t.tmp[:toStream])
+		for i := range t.tmp[bn:toStream] {
+			t.tmp[bn+i] = [2]float64{}
+		}
+
+		for i := range samples[:toStream] {
+			x := float64(t.pos+i) / float64(t.num)
+			in, out := t.curve(x), t.curve(1-x)
+			samples[i][0] = samples[i][0]*out + t.tmp[i][0]*in
+			samples[i][1] = samples[i][1]*out + t.tmp[i][1]*in
+		}
+
+		// keep going while any of the Streamers has something to say
+		if an < toStream && bn < toStream {
+			m := an
+			if bn > m {
+				m = bn
+			}
+			t.pos = t.num
+			return n + m, n+m > 0
+		}
+
+		t.pos += toStream
+		samples = samples[toStream:]
+		n += toStream
+	}
+	if len(samples) == 0 {
+		return n, true
+	}
+	bn, bok := t.b.Stream(samples)
+	return n + bn, n > 0 || bok
+}
+
+func (t *transition) Err() error {
+	return nil
+}
+
+// Crossfade takes zero or more Streamers and returns a Streamer which streams them one by one,
+// just like Seq, except the end of each Streamer is crossfaded with the beginning of the next one
+// over the duration of num samples, following the provided curve. If a Streamer is shorter than
+// num samples, the crossfade is shortened accordingly.
+//
+// In order to know where a Streamer ends, Crossfade reads num samples ahead of what it streams.
+//
+// Crossfade panics if num is negative.
+//
+// Crossfade does not propagate errors from the Streamers.
+func Crossfade(curve Curve, num int, s ...Streamer) Streamer {
+	if num < 0 {
+		panic(fmt.Errorf("crossfade: invalid number of samples: %v", num))
+	}
+	return &crossfade{
+		curve: curve,
+		num:   num,
+		s:     s,
+	}
+}
+
+type crossfade struct {
+	curve Curve
+	num   int
+	s     []Streamer
+	buf   [][2]float64 // samples read ahead from s[0], the first part may already be crossfaded
+	tmp   [512][2]float64
+}
+
+func (c *crossfade) Stream(samples [][2]float64) (n int, ok bool) {
+	// read ahead so that there are num more samples than we want to stream
+	for len(c.s) > 0 && len(c.buf) < len(samples)+c.num {
+		toStream := len(samples) + c.num - len(c.buf)
+		if toStream > len(c.tmp) {
+			toStream = len(c.tmp)
+		}
+		sn, sok := c.s[0].Stream(c.tmp[:toStream])
+		c.buf = append(c.buf, c.tmp[:sn]...)
+		if !sok {
+			c.next()
+		}
+	}
+	if len(c.buf) == 0 {
+		return 0, false
+	}
+	n = copy(samples, c.buf)
+	c.buf = c.buf[n:]
+	return n, true
+}
+
+// next drops the drained s[0] and crossfades its tail in buf with the beginning of the next
+// Streamer.
+func (c *crossfade) next() {
+	c.s = c.s[1:]
+	if len(c.s) == 0 {
+		return
+	}
+
+	fade := c.num
+	if fade > len(c.buf) {
+		fade = len(c.buf)
+	}
+	tail := c.buf[len(c.buf)-fade:]
+
+	drained := false
+	for pos := 0; pos < fade; {
+		toStream := fade - pos
+		if toStream > len(c.tmp) {
+			toStream = len(c.tmp)
+		}
+		sn := 0
+		if !drained {
+			// if the next Streamer is shorter than the crossfade, the rest is faded into silence
+			// and it gets dropped on the next read
+			sn, _ = c.s[0].Stream(c.tmp[:toStream])
+			drained = sn < toStream
+		}
+		for i := range tail[pos : pos+toStream] {
+			var in [2]float64
+			if i < sn {
+				in = c.tmp[i]
+			}
+			x := float64(pos+i+1) / float64(fade+1)
+			gin, gout := c.curve(x), c.curve(1-x)
+			tail[pos+i][0] = tail[pos+i][0]*gout + in[0]*gin
+			tail[pos+i][1] = tail[pos+i][1]*gout + in[1]*gin
+		}
+		pos += toStream
+	}
+}
+
+func (c *crossfade) Err() error {
+	return nil
+}
@@ -17,6 +17,33 @@ import (
 // Do not close the supplied Reader, instead, use the Close method of the returned
 // StreamSeekCloser when you want to release the resources.
 func Decode(r io.Reader) (s beep.StreamSeekCloser, format beep.Format, err error) {
+	d, format, err := decode(r)
+	if err != nil {
+		return nil, beep.Format{}, err
+	}
+	return d, format, nil
+}
+
+// DecodeMulti is the multichannel counterpart of Decode. It returns a MultiStreamSeekCloser which
+// streams all of the channels of the file. The layout is taken from the channel mask of the file
+// if present, otherwise beep.DefaultLayout is used.
+func DecodeMulti(r io.Reader) (s beep.MultiStreamSeekCloser, format beep.Format, err error) {
+	d, format, err := decode(r)
+	if err != nil {
+		return nil, beep.Format{}, err
+	}
+	layout := beep.Layout(d.mask)
+	if layout.NumChannels() != format.NumChannels {
+		if format.NumChannels > 18 {
+			d.Close()
+			return nil, beep.Format{}, fmt.Errorf("wav: unsupported number of channels: %d", format.NumChannels)
+		}
+		layout = beep.DefaultLayout(format.NumChannels)
+	}
+	return &multiDecoder{decoder: d, layout: layout}, format, nil
+}
+
+func decode(r io.Reader) (dp *decoder, format beep.Format, err error) {
 	d := decoder{r: r}
 	defer func() { // hacky way to always close r if an error occurred
 		if closer, ok := d.r.(io.Closer); ok {
@@ -77,6 +104,7 @@ func Decode(r io.Reader) (s beep.StreamSeekCloser, format beep.Format, err error
 				d.h.ByteRate = fmtchunk.ByteRate
 				d.h.BytesPerFrame = fmtchunk.BytesPerFrame
 				d.h.BitsPerSample = fmtchunk.BitsPerSample
+				d.mask = uint32(fmtchunk.ChannelMask)
 
 				// SubFormat is represented by GUID. Plain PCM is KSDATAFORMAT_SUBTYPE_PCM GUID.
 				// See https://docs.microsoft.com/en-us/windows-hardware/drivers/ddi/content/ksmedia/ns-ksmedia-waveformatextensible
@@ -195,11 +223,12 @@ type header struct {
 }
 
 type decoder struct {
-	r   io.Reader
-	h   header
-	hsz int32
-	pos int32
-	err error
+	r    io.Reader
+	h    header
+	hsz  int32
+	mask uint32
+	pos  int32
+	err  error
 }
 
 func (d *decoder) Stream(samples [][2]float64) (n int, ok bool) {
@@ -230,31 +259,70 @@ func (d *decoder) Stream(samples [][2]float64) (n int, ok bool) {
 		}
 	case d.h.BitsPerSample == 16 && d.h.NumChans == 1:
 		for i, j := 0, 0; i <= n-bytesPerFrame; i, j = i+bytesPerFrame, j+1 {
-			val := float64(int16(p[i+0])+int16(p[i+1])*(1<<8)) / (1<<16 - 1)
+			val := float64(int16(p[i+0])+int16(p[i+1])*(1<<8)) / (1<<15 - 1)
 			samples[j][0] = val
 			samples[j][1] = val
 		}
 	case d.h.BitsPerSample == 16 && d.h.NumChans >= 2:
 		for i, j := 0, 0; i <= n-bytesPerFrame; i, j = i+bytesPerFrame, j+1 {
-			samples[j][0] = float64(int16(p[i+0])+int16(p[i+1])*(1<<8)) / (1<<16 - 1)
-			samples[j][1] = float64(int16(p[i+2])+int16(p[i+3])*(1<<8)) / (1<<16 - 1)
+			samples[j][0] = float64(int16(p[i+0])+int16(p[i+1])*(1<<8)) / (1<<15 - 1)
+			samples[j][1] = float64(int16(p[i+2])+int16(p[i+3])*(1<<8)) / (1<<15 - 1)
 		}
 	case d.h.BitsPerSample == 24 && d.h.NumChans == 1:
 		for i, j := 0, 0; i <= n-bytesPerFrame; i, j = i+bytesPerFrame, j+1 {
-			val := float64((int32(p[i+0])<<8)+(int32(p[i+1])<<16)+(int32(p[i+2])<<24)) / (1 << 8) / (1<<24 - 1)
+			val := float64((int32(p[i+0])<<8)+(int32(p[i+1])<<16)+(int32(p[i+2])<<24)) / (1 << 8) / (1<<23 - 1)
 			samples[j][0] = val
 			samples[j][1] = val
 		}
 	case d.h.BitsPerSample == 24 && d.h.NumChans >= 2:
 		for i, j := 0, 0; i <= n-bytesPerFrame; i, j = i+bytesPerFrame, j+1 {
-			samples[j][0] = float64((int32(p[i+0])<<8)+(int32(p[i+1])<<16)+(int32(p[i+2])<<24)) / (1 << 8) / (1<<24 - 1)
-			samples[j][1] = float64((int32(p[i+3])<<8)+(int32(p[i+4])<<16)+(int32(p[i+5])<<24)) / (1 << 8) / (1<<24 - 1)
+			samples[j][0] = float64((int32(p[i+0])<<8)+(int32(p[i+1])<<16)+(int32(p[i+2])<<24)) / (1 << 8) / (1<<23 - 1)
+			samples[j][1] = float64((int32(p[i+3])<<8)+(int32(p[i+4])<<16)+(int32(p[i+5])<<24)) / (1 << 8) / (1<<23 - 1)
 		}
 	}
 	d.pos += int32(n)
 	return n / bytesPerFrame, true
 }
 
+type multiDecoder struct {
+	*decoder
+	layout beep.Layout
+}
+
+func (d *multiDecoder) Stream(samples [][]float64) (n int, ok bool) {
+	if d.err != nil || d.pos >= d.h.DataSize {
+		return 0, false
+	}
+	bytesPerFrame := int(d.h.BytesPerFrame)
+	numBytes := int32(len(samples) * bytesPerFrame)
+	if numBytes > d.h.DataSize-d.pos {
+		numBytes = d.h.DataSize - d.pos
+	}
+	p := make([]byte, numBytes)
+	n, err := io.ReadFull(d.r, p)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		d.err = err
+	}
+	format := beep.Format{
+		SampleRate:  beep.SampleRate(d.h.SampleRate),
+		NumChannels: int(d.h.NumChans),
+		Precision:   int(d.h.BitsPerSample / 8),
+	}
+	for i, j := 0, 0; i <= n-bytesPerFrame; i, j = i+bytesPerFrame, j+1 {
+		if d.h.BitsPerSample == 8 {
+			format.DecodeUnsignedMulti(p[i:], samples[j])
+		} else {
+			format.DecodeSignedMulti(p[i:], samples[j])
+		}
+	}
+	d.pos += int32(n)
+	return n / bytesPerFrame, true
+}
+
+func (d *multiDecoder) Layout() beep.Layout {
+	return d.layout
+}
+
 func (d *decoder) Err() error {
 	return d.err
 }
@@ -0,0 +1,235 @@
+package wav_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"math"
+	"os"
+	"testing"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/flac"
+	"github.com/faiface/beep/wav"
+)
+
+func TestDecodeMultiLevels(t *testing.T) {
+	for _, precision := range []int{1, 2, 3} {
+		for _, numChannels := range []int{1, 2} {
+			f, err := ioutil.TempFile("", "beep-wav")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.Remove(f.Name())
+
+			i := 0
+			s := beep.Take(1000, beep.StreamerFunc(func(samples [][2]float64) (n int, ok bool) {
+				for j := range samples {
+					x := 0.9 * math.Sin(float64(i)/10)
+					samples[j] = [2]float64{x, -x}
+					i++
+				}
+				return len(samples), true
+			}))
+			format := beep.Format{SampleRate: 44100, NumChannels: numChannels, Precision: precision}
+			if err := wav.Encode(f, s, format); err != nil {
+				t.Fatal(err)
+			}
+
+			decode := func(multi bool) [][2]float64 {
+				f.Seek(0, 0)
+				var s beep.Streamer
+				if multi {
+					m, _, err := wav.DecodeMulti(f)
+					if err != nil {
+						t.Fatal(err)
+					}
+					s = beep.Downmix(m)
+				} else {
+					s, _, err = wav.Decode(f)
+					if err != nil {
+						t.Fatal(err)
+					}
+				}
+				var result [][2]float64
+				buf := make([][2]float64, 512)
+				for {
+					n, ok := s.Stream(buf)
+					if !ok {
+						return result
+					}
+					result = append(result, buf[:n]...)
+				}
+			}
+			want, got := decode(false), decode(true)
+			// both decode at full scale, the same level as encoded
+			for j := range want {
+				x := 0.9 * math.Sin(float64(j)/10)
+				if numChannels == 1 {
+					// the channels cancel out in the mono mix
+					x = 0
+				}
+				if math.Abs(want[j][0]-x) > 0.01 {
+					t.Fatalf("precision %d, %d channels: Decode sample %v at %d, want about %.3f", precision, numChannels, want[j], j, x)
+				}
+			}
+			if len(got) != len(want) {
+				t.Fatalf("precision %d, %d channels: DecodeMulti streamed %d samples, Decode %d", precision, numChannels, len(got), len(want))
+			}
+			for j := range got {
+				for c := range got[j] {
+					if math.Abs(got[j][c]-want[j][c]) > 1e-9 {
+						t.Fatalf("precision %d, %d channels: DecodeMulti sample %v, Decode %v", precision, numChannels, got[j], want[j])
+					}
+				}
+			}
+			f.Close()
+		}
+	}
+}
+
+func TestDecodeMultiFLACLevels(t *testing.T) {
+	// the same audio decoded from WAV and from FLAC plays at the same level
+	const numSamples = 4096
+	for _, precision := range []int{2, 3} {
+		for _, numChannels := range []int{1, 2} {
+			full := math.Exp2(float64(8*precision-1)) - 1
+			data := make([][2]float64, numSamples)
+			channels := make([][]int32, numChannels)
+			for c := range channels {
+				channels[c] = make([]int32, numSamples)
+			}
+			for i := range data {
+				for c := range channels {
+					x := int32(0.9 * full * math.Sin(float64(i)/10+float64(c)))
+					channels[c][i] = x
+					data[i][c] = float64(x) / full
+				}
+				if numChannels == 1 {
+					data[i][1] = data[i][0]
+				}
+			}
+
+			f, err := ioutil.TempFile("", "beep-wav")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.Remove(f.Name())
+			format := beep.Format{SampleRate: 44100, NumChannels: numChannels, Precision: precision}
+			if err := wav.Encode(f, beep.Take(numSamples, &dataStreamer{data: data}), format); err != nil {
+				t.Fatal(err)
+			}
+			f.Seek(0, 0)
+			wavStreamer, _, err := wav.DecodeMulti(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			flacStreamer, _, err := flac.DecodeMulti(bytes.NewReader(encodeFLAC(channels, 8*precision)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, want := collectMulti(wavStreamer, numChannels), collectMulti(flacStreamer, numChannels)
+			if len(got) != len(want) {
+				t.Fatalf("precision %d, %d channels: WAV streamed %d samples, FLAC %d", precision, numChannels, len(got), len(want))
+			}
+			for i := range got {
+				for c := range got[i] {
+					if math.Abs(got[i][c]-want[i][c]) > 1e-4 {
+						t.Fatalf("precision %d, %d channels: WAV sample %v at %d, FLAC %v", precision, numChannels, got[i], i, want[i])
+					}
+				}
+			}
+			f.Close()
+		}
+	}
+}
+
+// encodeFLAC encodes the channels into a 44100 Hz FLAC stream of a single frame of 4096 verbatim
+// samples of 16 or 24 bits.
+func encodeFLAC(channels [][]int32, bits int) []byte {
+	const numSamples = 4096
+	var b bytes.Buffer
+	b.WriteString("fLaC")
+
+	// STREAMINFO, the last metadata block
+	b.Write([]byte{0x80, 0, 0, 34})
+	binary.Write(&b, binary.BigEndian, [2]uint16{numSamples, numSamples})
+	b.Write(make([]byte, 6)) // unknown frame sizes
+	binary.Write(&b, binary.BigEndian, uint64(44100)<<44|uint64(len(channels)-1)<<41|uint64(bits-1)<<36|numSamples)
+	b.Write(make([]byte, 16)) // unknown MD5 sum
+
+	frame := b.Len()
+	sampleSize := byte(4) // 16 bits
+	if bits == 24 {
+		sampleSize = 6
+	}
+	// sync code and fixed block size, block size of 4096 and 44.1 kHz, independent channels and
+	// the sample size, frame number 0
+	b.Write([]byte{0xff, 0xf8, 0xc9, byte(len(channels)-1)<<4 | sampleSize<<1, 0})
+	var crc8 byte
+	for _, x := range b.Bytes()[frame:] {
+		crc8 ^= x
+		for i := 0; i < 8; i++ {
+			if crc8&0x80 != 0 {
+				crc8 = crc8<<1 ^ 0x07
+			} else {
+				crc8 <<= 1
+			}
+		}
+	}
+	b.WriteByte(crc8)
+	for _, samples := range channels {
+		b.WriteByte(0x02) // verbatim subframe
+		for _, x := range samples {
+			for shift := bits - 8; shift >= 0; shift -= 8 {
+				b.WriteByte(byte(x >> uint(shift)))
+			}
+		}
+	}
+	var crc16 uint16
+	for _, x := range b.Bytes()[frame:] {
+		crc16 ^= uint16(x) << 8
+		for i := 0; i < 8; i++ {
+			if crc16&0x8000 != 0 {
+				crc16 = crc16<<1 ^ 0x8005
+			} else {
+				crc16 <<= 1
+			}
+		}
+	}
+	binary.Write(&b, binary.BigEndian, crc16)
+	return b.Bytes()
+}
+
+// dataStreamer streams the samples in data.
+type dataStreamer struct {
+	data [][2]float64
+	pos  int
+}
+
+func (ds *dataStreamer) Stream(samples [][2]float64) (n int, ok bool) {
+	n = copy(samples, ds.data[ds.pos:])
+	ds.pos += n
+	return n, n > 0
+}
+
+func (ds *dataStreamer) Err() error {
+	return nil
+}
+
+// collectMulti drains MultiStreamer s with numChannels channels and returns all of the samples
+// it streamed.
+func collectMulti(s beep.MultiStreamer, numChannels int) [][]float64 {
+	var result [][]float64
+	buf := beep.MakeMultiSamples(512, numChannels)
+	for {
+		n, ok := s.Stream(buf)
+		if !ok {
+			return result
+		}
+		for _, x := range buf[:n] {
+			result = append(result, append([]float64(nil), x...))
+		}
+	}
+}